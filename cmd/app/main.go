@@ -14,7 +14,9 @@ import (
 
 	"effective_mobile/internal/config"
 	"effective_mobile/internal/migrator"
+	"effective_mobile/internal/notifier"
 	"effective_mobile/internal/repository"
+	"effective_mobile/internal/scheduler"
 	"effective_mobile/internal/service"
 	v1 "effective_mobile/internal/transport/http/v1"
 	"effective_mobile/pkg/logger"
@@ -69,12 +71,44 @@ func main() {
 	// Инициализация репозитория и сервиса
 	repoSubs := repository.NewRepository(db, cfg.Environment)
 	subsService := service.NewSubscriptionService(repoSubs, cfg.Environment)
-
-	server := v1.NewServer(cfg.Port, subsService)
+	currencyService := service.NewCurrencyService(repoSubs, cfg.Environment)
+	catalogService := service.NewCatalogService(repoSubs, cfg.Environment)
+	budgetService := service.NewBudgetService(repoSubs, cfg.Environment)
+
+	reminderService := service.NewReminderService(repoSubs, notifier.NewLogNotifier(cfg.Environment), cfg.ReminderDays, cfg.ReminderLookbackDays, cfg.Environment)
+
+	// Фоновые задачи, останавливаются вместе с сервером
+	sched := scheduler.New(db, cfg.SchedulerLockKey, cfg.SchedulerRetry, cfg.Environment)
+	sched.Add(scheduler.Job{
+		Name:     "purge",
+		Interval: cfg.PurgeInterval,
+		Run: func(ctx context.Context) error {
+			_, err := subsService.PurgeDeleted(ctx, cfg.DeletedRetention)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:     "reminders",
+		Interval: cfg.ReminderInterval,
+		Run:      reminderService.Run,
+	})
+	sched.Add(scheduler.Job{
+		Name:     "budgets",
+		Interval: cfg.BudgetCheckInterval,
+		Run:      budgetService.Check,
+	})
+	schedCtx, stopSched := context.WithCancel(ctx)
+	defer stopSched()
+
+	server := v1.NewServer(cfg.Port, cfg.RequireIfMatch, subsService, currencyService, catalogService, budgetService)
 	server.RegisterHandlers()
 
 	wg := sync.WaitGroup{}
-	wg.Add(1)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sched.Run(schedCtx)
+	}()
 	go func() {
 		defer wg.Done()
 		lg.Info(ctx, "HTTP server listening on port %d", zap.Int("port", cfg.Port))
@@ -95,6 +129,7 @@ func main() {
 	if err := server.Stop(shutdownCtx); err != nil {
 		lg.Info(ctx, "server shutdown error: %v", zap.Error(err))
 	}
+	stopSched()
 
 	lg.Info(ctx, "Database connection pool closed")
 	wg.Wait()
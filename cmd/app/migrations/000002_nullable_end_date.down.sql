@@ -0,0 +1,3 @@
+UPDATE subscriptions SET end_date = to_char(now(), 'YYYY-MM') WHERE end_date IS NULL;
+
+ALTER TABLE subscriptions ALTER COLUMN end_date SET NOT NULL;
\ No newline at end of file
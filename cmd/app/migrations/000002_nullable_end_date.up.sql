@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ALTER COLUMN end_date DROP NOT NULL;
+
+UPDATE subscriptions SET end_date = NULL WHERE trim(end_date) = '';
\ No newline at end of file
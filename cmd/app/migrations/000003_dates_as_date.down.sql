@@ -0,0 +1,14 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_date_range,
+    DROP CONSTRAINT subscriptions_end_date_month,
+    DROP CONSTRAINT subscriptions_start_date_month;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE CHAR(7) USING to_char(start_date, 'YYYY-MM'),
+    ALTER COLUMN end_date TYPE CHAR(7) USING to_char(end_date, 'YYYY-MM');
+
+-- Строки из карантина возвращаются в исходном виде
+INSERT INTO subscriptions
+SELECT r.* FROM subscriptions_invalid_dates AS q, jsonb_populate_record(NULL::subscriptions, q.data) AS r;
+
+DROP TABLE subscriptions_invalid_dates;
\ No newline at end of file
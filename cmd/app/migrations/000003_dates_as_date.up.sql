@@ -0,0 +1,38 @@
+-- Разбор месяца в форматах MM-YYYY и YYYY-MM; для любой другой строки возвращает NULL
+CREATE FUNCTION pg_temp.parse_month(v TEXT) RETURNS DATE LANGUAGE sql STABLE AS $$
+    SELECT CASE
+        WHEN trim(v) ~ '^(0[1-9]|1[0-2])-\d{4}$' THEN to_date(trim(v), 'MM-YYYY')
+        WHEN trim(v) ~ '^\d{4}-(0[1-9]|1[0-2])$' THEN to_date(trim(v), 'YYYY-MM')
+    END
+$$;
+
+-- Подписки с неразбираемыми датами или end_date раньше start_date не дают сменить тип колонок.
+-- Они переносятся как есть в карантин для ручного разбора, остальные строки конвертируются.
+CREATE TABLE subscriptions_invalid_dates (
+    id INT PRIMARY KEY,
+    data JSONB NOT NULL,
+    reason TEXT NOT NULL,
+    quarantined_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO subscriptions_invalid_dates (id, data, reason)
+SELECT id, to_jsonb(s), CASE
+        WHEN pg_temp.parse_month(start_date) IS NULL THEN 'invalid start_date'
+        WHEN end_date IS NOT NULL AND pg_temp.parse_month(end_date) IS NULL THEN 'invalid end_date'
+        ELSE 'end_date before start_date'
+    END
+FROM subscriptions AS s
+WHERE pg_temp.parse_month(start_date) IS NULL
+    OR (end_date IS NOT NULL AND pg_temp.parse_month(end_date) IS NULL)
+    OR pg_temp.parse_month(end_date) < pg_temp.parse_month(start_date);
+
+DELETE FROM subscriptions WHERE id IN (SELECT id FROM subscriptions_invalid_dates);
+
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE DATE USING pg_temp.parse_month(start_date),
+    ALTER COLUMN end_date TYPE DATE USING pg_temp.parse_month(end_date);
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_start_date_month CHECK (start_date = date_trunc('month', start_date)),
+    ADD CONSTRAINT subscriptions_end_date_month CHECK (end_date = date_trunc('month', end_date)),
+    ADD CONSTRAINT subscriptions_date_range CHECK (end_date IS NULL OR end_date >= start_date);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE currency_rates;
+
+ALTER TABLE subscriptions DROP COLUMN currency;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE subscriptions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE currency_rates (
+    currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(18, 6) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (currency, rate_date)
+);
\ No newline at end of file
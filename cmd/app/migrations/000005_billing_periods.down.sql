@@ -0,0 +1,8 @@
+DROP FUNCTION subscription_charges(DATE, DATE, TEXT, INT, INT, DATE, DATE);
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_weekly_anchor,
+    DROP CONSTRAINT subscriptions_custom_interval,
+    DROP COLUMN anchor_day,
+    DROP COLUMN interval_months,
+    DROP COLUMN billing_period;
\ No newline at end of file
@@ -0,0 +1,56 @@
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period TEXT NOT NULL DEFAULT 'monthly'
+        CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    ADD COLUMN interval_months INT CHECK (interval_months > 0),
+    ADD COLUMN anchor_day SMALLINT NOT NULL DEFAULT 1 CHECK (anchor_day BETWEEN 1 AND 31),
+    ADD CONSTRAINT subscriptions_custom_interval CHECK ((billing_period = 'custom') = (interval_months IS NOT NULL)),
+    ADD CONSTRAINT subscriptions_weekly_anchor CHECK (billing_period <> 'weekly' OR anchor_day <= 7);
+
+-- Даты списаний подписки, попадающие в окно [window_start, window_end].
+-- start_month и end_month — первые числа месяцев начала и окончания подписки, end_month NULL
+-- для бессрочной. Для weekly anchor_day — день недели ISO, иначе — день месяца, который
+-- в коротких месяцах сдвигается на последний день.
+CREATE FUNCTION subscription_charges(
+    start_month DATE,
+    end_month DATE,
+    billing_period TEXT,
+    interval_months INT,
+    anchor_day INT,
+    window_start DATE,
+    window_end DATE
+) RETURNS SETOF DATE LANGUAGE sql IMMUTABLE AS $$
+    WITH bounds AS (
+        SELECT
+            GREATEST(start_month, window_start) AS lo,
+            LEAST(COALESCE((end_month + interval '1 month' - interval '1 day')::date, window_end), window_end) AS hi,
+            CASE billing_period
+                WHEN 'monthly' THEN 1
+                WHEN 'quarterly' THEN 3
+                WHEN 'yearly' THEN 12
+                WHEN 'custom' THEN interval_months
+            END AS step_months
+    )
+    SELECT charge.day::date
+    FROM bounds
+    CROSS JOIN LATERAL generate_series(
+        (start_month + (anchor_day - EXTRACT(ISODOW FROM start_month)::int + 7) % 7)::timestamp,
+        bounds.hi::timestamp,
+        interval '7 days'
+    ) AS charge(day)
+    WHERE billing_period = 'weekly' AND charge.day >= bounds.lo
+
+    UNION ALL
+
+    SELECT charge.day
+    FROM bounds
+    CROSS JOIN LATERAL generate_series(
+        start_month::timestamp,
+        bounds.hi::timestamp,
+        make_interval(months => bounds.step_months)
+    ) AS period(month)
+    CROSS JOIN LATERAL (SELECT (
+        period.month::date
+        + LEAST(anchor_day, EXTRACT(DAY FROM period.month + interval '1 month' - interval '1 day')::int) - 1
+    ) AS day) AS charge
+    WHERE billing_period <> 'weekly' AND charge.day BETWEEN bounds.lo AND bounds.hi
+$$;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE subscription_audit;
+
+DROP FUNCTION subscription_audit_append_only();
\ No newline at end of file
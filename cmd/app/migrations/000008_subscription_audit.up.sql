@@ -0,0 +1,24 @@
+CREATE TABLE subscription_audit (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')),
+    before JSONB,
+    after JSONB,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Без внешнего ключа: история переживает окончательное удаление подписки
+CREATE INDEX subscription_audit_subscription_idx ON subscription_audit (subscription_id, changed_at);
+
+-- Журнал только дополняется
+CREATE FUNCTION subscription_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscription_audit_append_only
+    BEFORE UPDATE OR DELETE ON subscription_audit
+    FOR EACH ROW EXECUTE FUNCTION subscription_audit_append_only();
\ No newline at end of file
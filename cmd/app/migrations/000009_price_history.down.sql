@@ -0,0 +1 @@
+DROP TABLE subscription_prices;
\ No newline at end of file
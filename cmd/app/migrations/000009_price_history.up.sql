@@ -0,0 +1,10 @@
+CREATE TABLE subscription_prices (
+    subscription_id INT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL CHECK (effective_from = date_trunc('month', effective_from)),
+    price INT NOT NULL CHECK (price >= 0),
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Текущая цена каждой подписки действует с её начала
+INSERT INTO subscription_prices (subscription_id, effective_from, price)
+SELECT id, start_date, price FROM subscriptions;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE subscription_status_periods;
+
+ALTER TABLE subscriptions DROP COLUMN status;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE subscriptions ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
+    CONSTRAINT subscriptions_status CHECK (status IN ('trial', 'active', 'paused', 'cancelled'));
+
+CREATE TABLE subscription_status_periods (
+    subscription_id INT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL CHECK (effective_from = date_trunc('month', effective_from)),
+    status TEXT NOT NULL CHECK (status IN ('trial', 'active', 'paused', 'cancelled')),
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Существующие подписки активны с самого начала
+INSERT INTO subscription_status_periods (subscription_id, effective_from, status)
+SELECT id, start_date, status FROM subscriptions;
\ No newline at end of file
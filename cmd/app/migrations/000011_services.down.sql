@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions DROP COLUMN service_id;
+
+DROP TABLE service_aliases;
+
+DROP TABLE services;
\ No newline at end of file
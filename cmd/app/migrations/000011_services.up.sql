@@ -0,0 +1,34 @@
+CREATE TABLE services (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    category TEXT,
+    default_price INT CHECK (default_price >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB'
+);
+
+CREATE UNIQUE INDEX services_name_key ON services (lower(name));
+
+CREATE TABLE service_aliases (
+    service_id INT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX service_aliases_alias_key ON service_aliases (lower(alias));
+CREATE INDEX service_aliases_service_idx ON service_aliases (service_id);
+
+-- Названия, которые различаются только регистром и пробелами по краям, становятся одним сервисом
+INSERT INTO services (name, currency)
+SELECT DISTINCT ON (lower(trim(name))) trim(name), currency
+FROM subscriptions
+ORDER BY lower(trim(name)), id;
+
+ALTER TABLE subscriptions ADD COLUMN service_id INT REFERENCES services (id);
+
+UPDATE subscriptions AS s
+SET service_id = sv.id, name = sv.name
+FROM services AS sv
+WHERE lower(trim(s.name)) = lower(sv.name);
+
+ALTER TABLE subscriptions ALTER COLUMN service_id SET NOT NULL;
+
+CREATE INDEX subscriptions_service_id_idx ON subscriptions (service_id);
\ No newline at end of file
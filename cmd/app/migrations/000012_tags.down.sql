@@ -0,0 +1,5 @@
+DROP INDEX services_category_idx;
+
+DROP TABLE subscription_tags;
+
+DROP TABLE tags;
\ No newline at end of file
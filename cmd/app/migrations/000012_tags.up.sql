@@ -0,0 +1,14 @@
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE CHECK (name = lower(name) AND name <> '')
+);
+
+CREATE TABLE subscription_tags (
+    subscription_id INT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX subscription_tags_tag_idx ON subscription_tags (tag_id);
+
+CREATE INDEX services_category_idx ON services (lower(category));
\ No newline at end of file
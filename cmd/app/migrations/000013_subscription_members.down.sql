@@ -0,0 +1 @@
+DROP TABLE subscription_members;
\ No newline at end of file
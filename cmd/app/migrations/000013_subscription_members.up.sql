@@ -0,0 +1,8 @@
+CREATE TABLE subscription_members (
+    subscription_id INT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    share INT NOT NULL CHECK (share > 0),
+    PRIMARY KEY (subscription_id, user_id)
+);
+
+CREATE INDEX subscription_members_user_idx ON subscription_members (user_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE budget_events;
+
+DROP TABLE budgets;
\ No newline at end of file
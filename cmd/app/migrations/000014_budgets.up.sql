@@ -0,0 +1,29 @@
+CREATE TABLE budgets (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    category TEXT,
+    service_id INT REFERENCES services (id) ON DELETE CASCADE,
+    monthly_limit INT NOT NULL CHECK (monthly_limit > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    CONSTRAINT budgets_single_scope CHECK (category IS NULL OR service_id IS NULL)
+);
+
+-- Один бюджет на пользователя и область: общий, категория или сервис
+CREATE UNIQUE INDEX budgets_scope_key ON budgets (user_id, lower(COALESCE(category, '')), COALESCE(service_id, 0));
+
+-- События выхода за бюджет и возврата в него. Журнал не ссылается на budgets,
+-- чтобы события оставались доступны потребителям и после удаления бюджета.
+CREATE TABLE budget_events (
+    id BIGSERIAL PRIMARY KEY,
+    budget_id INT NOT NULL,
+    user_id UUID NOT NULL,
+    month DATE NOT NULL CHECK (month = date_trunc('month', month)),
+    kind TEXT NOT NULL CHECK (kind IN ('exceeded', 'recovered')),
+    projected BIGINT NOT NULL,
+    monthly_limit INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX budget_events_budget_idx ON budget_events (budget_id, month, id);
+CREATE INDEX budget_events_user_idx ON budget_events (user_id, id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE subscription_reminders;
\ No newline at end of file
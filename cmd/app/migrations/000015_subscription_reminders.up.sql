@@ -0,0 +1,9 @@
+-- Отправленные напоминания: каждое напоминание доставляется участнику подписки один раз
+CREATE TABLE subscription_reminders (
+    subscription_id INT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('renewal', 'ending')),
+    due_date DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, user_id, kind, due_date)
+);
\ No newline at end of file
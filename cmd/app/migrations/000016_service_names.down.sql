@@ -0,0 +1,5 @@
+DROP TRIGGER service_aliases_sync_name ON service_aliases;
+DROP TRIGGER services_sync_name ON services;
+DROP FUNCTION service_aliases_sync_name();
+DROP FUNCTION services_sync_name();
+DROP TABLE service_names;
\ No newline at end of file
@@ -0,0 +1,50 @@
+-- Общее пространство имён каталога: название сервиса и его псевдонимы не могут совпадать
+-- без учёта регистра ни с названием, ни с псевдонимом другого сервиса. Таблица ведётся
+-- триггерами на services и service_aliases, уникальность гарантирует первичный ключ.
+CREATE TABLE service_names (
+    name_key TEXT PRIMARY KEY,
+    service_id INT NOT NULL REFERENCES services (id) ON DELETE CASCADE
+);
+
+-- Псевдонимы, совпавшие с названием сервиса до появления ограничения, удаляются: название важнее
+DELETE FROM service_aliases AS a
+USING services AS sv
+WHERE lower(a.alias) = lower(sv.name);
+
+INSERT INTO service_names (name_key, service_id)
+SELECT lower(name), id FROM services
+UNION ALL
+SELECT lower(alias), service_id FROM service_aliases;
+
+CREATE FUNCTION services_sync_name() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF lower(NEW.name) = lower(OLD.name) THEN
+            RETURN NULL;
+        END IF;
+        DELETE FROM service_names WHERE name_key = lower(OLD.name) AND service_id = OLD.id;
+    END IF;
+    INSERT INTO service_names (name_key, service_id) VALUES (lower(NEW.name), NEW.id);
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER services_sync_name
+    AFTER INSERT OR UPDATE OF name ON services
+    FOR EACH ROW EXECUTE FUNCTION services_sync_name();
+
+CREATE FUNCTION service_aliases_sync_name() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM service_names WHERE name_key = lower(OLD.alias) AND service_id = OLD.service_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO service_names (name_key, service_id) VALUES (lower(NEW.alias), NEW.service_id);
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER service_aliases_sync_name
+    AFTER INSERT OR UPDATE OR DELETE ON service_aliases
+    FOR EACH ROW EXECUTE FUNCTION service_aliases_sync_name();
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE reminder_runs;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- День последнего успешного запуска напоминаний: следующий запуск начинает окно с него,
+-- чтобы не пропустить события, пока планировщик не работал или доставка не удавалась
+CREATE TABLE reminder_runs (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    run_date DATE NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
\ No newline at end of file
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,27 @@ type Config struct {
 
 	BaseURL string `env:"BASE_URL" env-default:"http://localhost:8080"`
 
+	// Требовать If-Match при изменении и удалении подписки по ID
+	RequireIfMatch bool `env:"REQUIRE_IF_MATCH" env-default:"true"`
+
+	// Мягко удалённые подписки окончательно удаляются спустя DeletedRetention, проверка раз в PurgeInterval
+	DeletedRetention time.Duration `env:"DELETED_RETENTION" env-default:"720h"`
+	PurgeInterval    time.Duration `env:"PURGE_INTERVAL" env-default:"1h"`
+
+	// Фоновые задачи выполняет одна реплика — владелец advisory-блокировки SchedulerLockKey,
+	// остальные пытаются занять её раз в SchedulerRetry
+	SchedulerLockKey int64         `env:"SCHEDULER_LOCK_KEY" env-default:"2025110101"`
+	SchedulerRetry   time.Duration `env:"SCHEDULER_RETRY" env-default:"30s"`
+
+	// Напоминания о списаниях и окончании подписок за ReminderDays дней, проверка раз в ReminderInterval.
+	// Пропущенные напоминания досылаются не более чем за ReminderLookbackDays прошедших дней
+	ReminderDays         int           `env:"REMINDER_DAYS" env-default:"3"`
+	ReminderInterval     time.Duration `env:"REMINDER_INTERVAL" env-default:"1h"`
+	ReminderLookbackDays int           `env:"REMINDER_LOOKBACK_DAYS" env-default:"7"`
+
+	// Переходы бюджетов через лимит проверяются и записываются в журнал событий раз в BudgetCheckInterval
+	BudgetCheckInterval time.Duration `env:"BUDGET_CHECK_INTERVAL" env-default:"15m"`
+
 	// PostgreSQL
 	DBUser     string `env:"DB_USER" env-default:"appuser"`
 	DBPassword string `env:"DB_PASSWORD" env-default:"123"`
@@ -50,6 +72,38 @@ func ParseConfigFromEnv() (*Config, error) {
 	if err := cleanenv.ReadEnv(cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config from env: %w", err)
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return cfg, nil
 }
+
+// validate проверяет интервалы фоновых задач: нулевой или отрицательный интервал приводит к панике
+// time.NewTicker, а нулевой SchedulerRetry — к непрерывным попыткам занять блокировку.
+func (c *Config) validate() error {
+	var errs []error
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"PURGE_INTERVAL", c.PurgeInterval},
+		{"SCHEDULER_RETRY", c.SchedulerRetry},
+		{"REMINDER_INTERVAL", c.ReminderInterval},
+		{"BUDGET_CHECK_INTERVAL", c.BudgetCheckInterval},
+	} {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", d.name, d.value))
+		}
+	}
+	if c.DeletedRetention < 0 {
+		errs = append(errs, fmt.Errorf("DELETED_RETENTION must not be negative, got %s", c.DeletedRetention))
+	}
+	if c.ReminderDays < 0 {
+		errs = append(errs, fmt.Errorf("REMINDER_DAYS must not be negative, got %d", c.ReminderDays))
+	}
+	if c.ReminderLookbackDays < 0 {
+		errs = append(errs, fmt.Errorf("REMINDER_LOOKBACK_DAYS must not be negative, got %d", c.ReminderLookbackDays))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/internal/service"
+	"effective_mobile/pkg/problem"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// maxEventsLimit — наибольший размер страницы журнала событий бюджетов.
+const maxEventsLimit = 100
+
+// BudgetHandler handles user budget endpoints
+type BudgetHandler struct {
+	Service *service.BudgetService
+}
+
+// List godoc
+// @Summary List budgets of a user
+// @Description List the monthly budgets of the user in creation order
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Success 200 {array} models.Budget
+// @Failure 400 {object} problem.Problem "invalid user_id"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets [get]
+func (h *BudgetHandler) List(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.Service.Select(ctx, userID)
+	if err != nil {
+		writeServiceError(w, r, err, "list budgets")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(budgets); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// Create godoc
+// @Summary Create a budget
+// @Description Define a monthly spending limit of the user: overall, for a service category or for one catalog service.
+// @Description Only one budget may exist per scope.
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Param budget body models.Budget true "Budget data, id and user_id are ignored"
+// @Success 201 {object} models.Budget
+// @Failure 400 {object} problem.Problem "invalid user_id or JSON"
+// @Failure 409 {object} problem.Problem "budget for this scope already exists"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets [post]
+func (h *BudgetHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var budget models.Budget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	budget.ID = 0
+	budget.UserID = userID
+
+	if err := h.Service.Insert(ctx, &budget); err != nil {
+		writeServiceError(w, r, err, "insert budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(budget); err != nil {
+		log.Printf("failed to encode budget to JSON: %v", err)
+	}
+}
+
+// UpdateByID godoc
+// @Summary Replace a budget
+// @Description Replace the scope, limit and currency of the user's budget
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Param id path int true "Budget ID"
+// @Param budget body models.Budget true "Budget data, id and user_id are ignored"
+// @Success 200 {object} models.Budget
+// @Failure 400 {object} problem.Problem "invalid user_id, id or JSON"
+// @Failure 404 {object} problem.Problem "budget not found"
+// @Failure 409 {object} problem.Problem "budget for this scope already exists"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets/{id} [put]
+func (h *BudgetHandler) UpdateByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	var budget models.Budget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	budget.ID = id
+	budget.UserID = userID
+
+	if err := h.Service.UpdateByID(ctx, &budget); err != nil {
+		writeServiceError(w, r, err, "update budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(budget); err != nil {
+		log.Printf("failed to encode budget to JSON: %v", err)
+	}
+}
+
+// DeleteByID godoc
+// @Summary Delete a budget
+// @Description Delete the user's budget. Its recorded events stay in the event log.
+// @Tags budgets
+// @Param user_id path string true "User ID (UUID)"
+// @Param id path int true "Budget ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} problem.Problem "invalid user_id or id"
+// @Failure 404 {object} problem.Problem "budget not found"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets/{id} [delete]
+func (h *BudgetHandler) DeleteByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	if err := h.Service.DeleteByID(ctx, userID, id); err != nil {
+		writeServiceError(w, r, err, "delete budget")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Status godoc
+// @Summary Budget status of a user
+// @Description Compare the projected spending of the current and next months with every budget of the user.
+// @Description Projections use the same rules as /subscriptions/sum: all charges of the month at the price in effect,
+// @Description only active months, the user's share of shared subscriptions, converted to the budget currency.
+// @Description The endpoint is read-only: a background job records limit crossings in the budget event log.
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Success 200 {array} models.BudgetStatus
+// @Failure 400 {object} problem.Problem "invalid user_id"
+// @Failure 422 {object} problem.Problem "exchange rate is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets/status [get]
+func (h *BudgetHandler) Status(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	statuses, err := h.Service.Status(ctx, userID)
+	if errors.Is(err, models.ErrMissingExchangeRate) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, err, "compute budget status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// Events godoc
+// @Summary Budget events of a user
+// @Description List the user's over-budget transitions in recording order. Pass the id of the last processed event
+// @Description as after to read only newer ones.
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Param after query int false "Return events with a greater id" default(0)
+// @Param limit query int false "Limit" default(100) maximum(100)
+// @Success 200 {array} models.BudgetEvent
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/budgets/events [get]
+func (h *BudgetHandler) Events(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	after, limit, err := parseEventsPage(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.Service.Events(ctx, userID, after, limit)
+	if err != nil {
+		writeServiceError(w, r, err, "list budget events")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// AllEvents godoc
+// @Summary Budget events of all users
+// @Description Feed of over-budget transitions of every user in recording order for consumers inside the system.
+// @Description Pass the id of the last processed event as after to read only newer ones.
+// @Tags admin
+// @Produce json
+// @Param after query int false "Return events with a greater id" default(0)
+// @Param limit query int false "Limit" default(100) maximum(100)
+// @Success 200 {array} models.BudgetEvent
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /admin/budget-events [get]
+func (h *BudgetHandler) AllEvents(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	after, limit, err := parseEventsPage(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.Service.AllEvents(ctx, after, limit)
+	if err != nil {
+		writeServiceError(w, r, err, "list budget events")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// parseEventsPage разбирает параметры страницы журнала событий: after (по умолчанию 0) и limit.
+func parseEventsPage(params url.Values) (int64, int, error) {
+	var after int64
+	if s := params.Get("after"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			return 0, 0, errors.New("invalid after value")
+		}
+		after = v
+	}
+
+	limit := maxEventsLimit
+	if s := params.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			return 0, 0, errors.New("invalid limit value")
+		}
+		if v > maxEventsLimit {
+			return 0, 0, fmt.Errorf("limit must not exceed %d", maxEventsLimit)
+		}
+		limit = v
+	}
+	return after, limit, nil
+}
+
+// pathUserID извлекает идентификатор пользователя из пути запроса; при ошибке отвечает 400.
+// Нулевой UUID не принадлежит ни одному пользователю и тоже отклоняется.
+func pathUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil || userID == uuid.Nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid user_id",
+			problem.FieldError{Field: "user_id", Message: "must be a non-nil UUID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/internal/service"
+	"effective_mobile/pkg/problem"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// CatalogHandler handles service catalog endpoints
+type CatalogHandler struct {
+	Service *service.CatalogService
+}
+
+// List godoc
+// @Summary List services
+// @Description List the service catalog in alphabetical order
+// @Tags services
+// @Produce json
+// @Param category query string false "Category"
+// @Success 200 {array} models.Service
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /services [get]
+func (h *CatalogHandler) List(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	services, err := h.Service.Select(ctx, r.URL.Query().Get("category"))
+	if err != nil {
+		writeServiceError(w, r, err, "list services")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(services); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// Create godoc
+// @Summary Create a service
+// @Description Add a service to the catalog. Subscription names matching the name or one of the aliases
+// @Description (case-insensitive) are linked to this service and stored under its name.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body models.Service true "Service data"
+// @Success 201 {object} models.Service
+// @Failure 400 {object} problem.Problem "invalid JSON"
+// @Failure 409 {object} problem.Problem "name or alias already belongs to another service"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /services [post]
+func (h *CatalogHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var svc models.Service
+	if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	svc.ID = 0
+
+	if err := h.Service.Insert(ctx, &svc); err != nil {
+		writeServiceError(w, r, err, "insert service")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(svc); err != nil {
+		log.Printf("failed to encode service to JSON: %v", err)
+	}
+}
+
+// GetByID godoc
+// @Summary Get a service
+// @Description Get a catalog service with its aliases
+// @Tags services
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "service not found"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	svc, err := h.Service.SelectByID(ctx, id)
+	if err != nil {
+		writeServiceError(w, r, err, "get service")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(svc); err != nil {
+		log.Printf("failed to encode service to JSON: %v", err)
+	}
+}
+
+// UpdateByID godoc
+// @Summary Replace a service
+// @Description Replace all fields and aliases of the service. A new name is written to all its subscriptions.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param service body models.Service true "Service data"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} problem.Problem "invalid id or JSON"
+// @Failure 404 {object} problem.Problem "service not found"
+// @Failure 409 {object} problem.Problem "name or alias already belongs to another service"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	var svc models.Service
+	if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	svc.ID = id
+
+	if err := h.Service.UpdateByID(ctx, &svc); err != nil {
+		writeServiceError(w, r, err, "update service")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(svc); err != nil {
+		log.Printf("failed to encode service to JSON: %v", err)
+	}
+}
+
+// DeleteByID godoc
+// @Summary Delete a service
+// @Description Remove a service from the catalog. Services referenced by subscriptions, including deleted ones, cannot be removed.
+// @Tags services
+// @Param id path int true "Service ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "service not found"
+// @Failure 409 {object} problem.Problem "service is used by subscriptions"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	if err := h.Service.DeleteByID(ctx, id); err != nil {
+		writeServiceError(w, r, err, "delete service")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/internal/service"
+	"effective_mobile/pkg/problem"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CurrencyHandler handles exchange rate administration endpoints
+type CurrencyHandler struct {
+	Service *service.CurrencyService
+}
+
+// LoadRates godoc
+// @Summary Load exchange rates
+// @Description Upsert exchange rates to the base currency (RUB). Accepts a JSON array or a text/csv body with the columns currency,date,rate; the header line is optional
+// @Tags admin
+// @Accept json
+// @Accept text/csv
+// @Param rates body []models.CurrencyRate true "Exchange rates"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} problem.Problem "invalid body or rates"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /admin/currency-rates [post]
+func (h *CurrencyHandler) LoadRates(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var rates []models.CurrencyRate
+	var err error
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		rates, err = parseRatesCSV(r.Body)
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&rates)
+	}
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+
+	if err := h.Service.LoadRates(ctx, rates); err != nil {
+		if errors.Is(err, service.ErrInvalidRate) {
+			problem.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeServiceError(w, r, err, "load currency rates")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRates godoc
+// @Summary List exchange rates
+// @Description List loaded exchange rates to the base currency (RUB)
+// @Tags admin
+// @Produce json
+// @Param currency query string false "Currency code"
+// @Success 200 {array} models.CurrencyRate
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /admin/currency-rates [get]
+func (h *CurrencyHandler) ListRates(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+
+	rates, err := h.Service.SelectRates(ctx, currency)
+	if err != nil {
+		writeServiceError(w, r, err, "list currency rates")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rates); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// parseRatesCSV читает строки вида currency,date,rate; строка заголовка пропускается.
+func parseRatesCSV(body io.Reader) ([]models.CurrencyRate, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	firstLine := 1
+	if len(records) > 0 && strings.EqualFold(records[0][0], "currency") {
+		records = records[1:]
+		firstLine++
+	}
+
+	rates := make([]models.CurrencyRate, 0, len(records))
+	for i, rec := range records {
+		line := firstLine + i
+		date, err := models.ParseDate(rec[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rate, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, rec[2])
+		}
+
+		rates = append(rates, models.CurrencyRate{Currency: rec[0], Date: date, Rate: rate})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/problem"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// writeServiceError отвечает статусом, соответствующим ошибке предметной области:
+// ErrNotFound — 404, ErrConflict — 409, ErrStaleVersion — 412,
+// ErrValidation — 422 (для *models.ValidationError с перечнем нарушенных полей).
+// Остальные ошибки логируются и отдаются как 500 без подробностей.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, action string) {
+	var verr *models.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		fields := make([]problem.FieldError, len(verr.Violations))
+		for i, v := range verr.Violations {
+			fields[i] = problem.FieldError{Field: v.Field, Message: v.Message}
+		}
+		problem.Error(w, r, http.StatusUnprocessableEntity, "request has invalid fields", fields...)
+	case errors.Is(err, models.ErrNotFound):
+		problem.Error(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		problem.Error(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrStaleVersion):
+		problem.Error(w, r, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, models.ErrValidation):
+		problem.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+	default:
+		log.Printf("failed to %s: %v", action, err)
+		problem.Error(w, r, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"effective_mobile/pkg/problem"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag возвращает сильный ETag для версии подписки.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// expectedVersion читает версию из If-Match. Отсутствие заголовка или "*" дают 0 — любую версию,
+// если заголовок не обязателен. При ошибке ответ уже записан в w, и вызывающий должен просто выйти.
+func expectedVersion(w http.ResponseWriter, r *http.Request, required bool) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		if required {
+			problem.Error(w, r, http.StatusPreconditionRequired, "If-Match header with the subscription ETag is required")
+			return 0, false
+		}
+		return 0, true
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	// Версия сравнивается целиком, поэтому слабый ETag принимается наравне с сильным
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		problem.Error(w, r, http.StatusBadRequest, "If-Match must be a single ETag returned by the API")
+		return 0, false
+	}
+	return version, true
+}
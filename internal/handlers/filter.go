@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"effective_mobile/internal/models"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxListLimit — наибольший размер страницы списка подписок.
+const maxListLimit = 100
+
+// parseSubscriptionFilter разбирает параметры отбора, сортировки и пагинации списка подписок.
+func parseSubscriptionFilter(params url.Values) (models.SubscriptionFilter, error) {
+	f := models.SubscriptionFilter{
+		Name:       params.Get("name"),
+		NamePrefix: params.Get("name_prefix"),
+		Status:     params.Get("status"),
+		Category:   params.Get("category"),
+		Tags:       tagList(params, "tags"),
+		Limit:      10,
+	}
+	var err error
+
+	if offsetStr := params.Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			f.Offset = o
+		} else {
+			return f, errors.New("invalid offset value")
+		}
+	}
+
+	if limitStr := params.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			f.Limit = l
+		} else {
+			return f, errors.New("invalid limit value")
+		}
+		if f.Limit > maxListLimit {
+			return f, fmt.Errorf("limit must not exceed %d", maxListLimit)
+		}
+	}
+
+	if f.Status != "" && !models.IsKnownStatus(f.Status) {
+		return f, errors.New("invalid status value")
+	}
+
+	if userIDStr := params.Get("user_id"); userIDStr != "" {
+		if f.UserID, err = uuid.Parse(userIDStr); err != nil {
+			return f, errors.New("invalid user_id")
+		}
+	}
+
+	if f.IncludeDeleted, err = optionalBool(params, "include_deleted"); err != nil {
+		return f, err
+	}
+
+	if f.PriceMin, err = optionalInt(params, "price_min"); err != nil {
+		return f, err
+	}
+	if f.PriceMax, err = optionalInt(params, "price_max"); err != nil {
+		return f, err
+	}
+
+	months := []struct {
+		name string
+		dst  **models.YearMonth
+	}{
+		{"active_on", &f.ActiveOn},
+		{"start_from", &f.StartFrom},
+		{"start_to", &f.StartTo},
+		{"end_from", &f.EndFrom},
+		{"end_to", &f.EndTo},
+	}
+	for _, m := range months {
+		if *m.dst, err = optionalYearMonth(params, m.name); err != nil {
+			return f, err
+		}
+	}
+
+	if f.Sort, err = models.ParseSort(params.Get("sort")); err != nil {
+		return f, err
+	}
+
+	// Курсор задаёт позицию сам, поэтому вместе с offset не принимается
+	if cursor := params.Get("cursor"); cursor != "" {
+		if f.Offset != 0 {
+			return f, errors.New("cursor and offset cannot be combined")
+		}
+		c, err := models.DecodeCursor(cursor, f.Sort)
+		if err != nil {
+			return f, err
+		}
+		f.After = &c
+	}
+
+	return f, nil
+}
+
+// tagList разбирает список тегов через запятую; теги приводятся к нижнему регистру, пустые пропускаются.
+func tagList(params url.Values, name string) []string {
+	var tags []string
+	for _, tag := range strings.Split(params.Get(name), ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func optionalInt(params url.Values, name string) (*int, error) {
+	s := params.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value", name)
+	}
+	return &v, nil
+}
+
+func optionalBool(params url.Values, name string) (bool, error) {
+	s := params.Get(name)
+	if s == "" {
+		return false, nil
+	}
+
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value", name)
+	}
+	return v, nil
+}
+
+func optionalYearMonth(params url.Values, name string) (*models.YearMonth, error) {
+	s := params.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+
+	ym, err := models.ParseYearMonth(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &ym, nil
+}
@@ -4,10 +4,17 @@ import (
 	"context"
 	"effective_mobile/internal/models"
 	"effective_mobile/internal/service"
+	"effective_mobile/pkg/problem"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,94 +22,137 @@ import (
 // SubscriptionHandler handles subscription-related endpoints
 type SubscriptionHandler struct {
 	Service *service.SubscriptionService
+	// RequireIfMatch запрещает изменять и удалять подписку по ID без заголовка If-Match.
+	RequireIfMatch bool
 }
 
 // Create godoc
 // @Summary Create a subscription
-// @Description Create a new subscription record
+// @Description Create a new subscription record. name is resolved through the service catalog (aliases, case-insensitive)
+// @Description and stored as the canonical service name; an unknown name adds a new service. Instead of name a service_id may be given.
+// @Description status may be trial or active (default); later it changes only through the activate, pause and cancel endpoints.
+// @Description members split every charge by their share parts; without members the owner user_id pays in full.
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param subscription body models.Subscription true "Subscription data"
 // @Success 201 {object} models.Subscription
-// @Failure 400 {string} string "invalid JSON or invalid fields"
-// @Failure 500 {string} string "internal server error"
-// @Router /subscriptions/create [post]
+// @Header 201 {string} ETag "subscription version"
+// @Failure 400 {object} problem.Problem "invalid JSON"
+// @Failure 409 {object} problem.Problem "subscription conflicts with an existing one"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions [post]
 func (h *SubscriptionHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	var sub models.Subscription
 
 	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	if len(sub.StartDate) != 7 || len(sub.EndDate) != 7 {
-		http.Error(w, "invalid date format, must be YYYY-MM", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
 		return
 	}
 
 	if err := h.Service.Insert(ctx, &sub); err != nil {
-		log.Printf("failed to insert subscription: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "insert subscription")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(sub)
 }
 
 // List godoc
 // @Summary List subscriptions
-// @Description List all subscriptions with pagination
+// @Description List subscriptions with filters, sorting and pagination.
+// @Description Without cursor and include_total the response is a plain array (offset pagination).
+// @Description With cursor (empty for the first page) or include_total=true the response is a models.SubscriptionPage
+// @Description envelope; pass its next_cursor back as cursor with the same sort to get the next page.
 // @Tags subscriptions
 // @Produce json
-// @Param offset query int false "Offset" default(0)
-// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset, cannot be combined with cursor" default(0)
+// @Param limit query int false "Limit" default(10) maximum(100)
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param include_total query bool false "Add the total number of matching subscriptions"
+// @Param user_id query string false "User ID (UUID)"
+// @Param name query string false "Service name or alias from the catalog, case-insensitive"
+// @Param name_prefix query string false "Case-insensitive service name prefix"
+// @Param status query string false "Current status" Enums(trial, active, paused, cancelled)
+// @Param category query string false "Category of the catalog service, case-insensitive"
+// @Param tags query string false "Comma-separated tags, the subscription must have all of them" example(family,music)
+// @Param price_min query int false "Minimal price"
+// @Param price_max query int false "Maximal price"
+// @Param active_on query string false "Month YYYY-MM the subscription is active in"
+// @Param start_from query string false "Earliest start month YYYY-MM"
+// @Param start_to query string false "Latest start month YYYY-MM"
+// @Param end_from query string false "Earliest end month YYYY-MM"
+// @Param end_to query string false "Latest end month YYYY-MM"
+// @Param include_deleted query bool false "Include soft-deleted subscriptions (admin)"
+// @Param sort query string false "Comma-separated fields id, name, price, user_id, start_date, end_date; prefix with - for descending" example(-price,name)
 // @Success 200 {array} models.Subscription
-// @Failure 400 {string} string "invalid parameters"
-// @Router /subscriptions/list [get]
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions [get]
 func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	params := r.URL.Query()
 
-	offset := 0
-	limit := 10
+	filter, err := parseSubscriptionFilter(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if offsetStr := params.Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		} else {
-			http.Error(w, "invalid offset value", http.StatusBadRequest)
+	params := r.URL.Query()
+	withTotal := false
+	if totalStr := params.Get("include_total"); totalStr != "" {
+		withTotal, err = strconv.ParseBool(totalStr)
+		if err != nil {
+			problem.Error(w, r, http.StatusBadRequest, "invalid include_total value")
 			return
 		}
 	}
 
-	if limitStr := params.Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		} else {
-			http.Error(w, "invalid limit value", http.StatusBadRequest)
+	// Без cursor и include_total сохраняется прежний ответ — голый массив
+	if !params.Has("cursor") && !withTotal {
+		subs, err := h.Service.Select(ctx, filter)
+		if err != nil {
+			writeServiceError(w, r, err, "list subscriptions")
 			return
 		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(subs)
+		return
 	}
 
-	subs := h.Service.Select(ctx, limit, offset)
+	page, err := h.Service.SelectPage(ctx, filter, withTotal)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			problem.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeServiceError(w, r, err, "list subscriptions")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(subs)
+	json.NewEncoder(w).Encode(page)
 }
 
 // Get godoc
 // @Summary Get a subscription
-// @Description Get subscription by user_id and service_name
+// @Description Get subscription by user_id and service_name. Deprecated: use GET /subscriptions/{id}
 // @Tags subscriptions
+// @Deprecated
 // @Produce json
 // @Param user_id query string true "User ID (UUID)"
 // @Param service_name query string true "Service Name"
 // @Success 200 {object} models.Subscription
-// @Failure 400 {string} string "missing or invalid parameters"
-// @Failure 500 {string} string "internal server error"
+// @Header 200 {string} ETag "subscription version, send it back in If-Match"
+// @Failure 400 {object} problem.Problem "missing or invalid parameters"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "several subscriptions match, use the ID route"
+// @Failure 500 {object} problem.Problem "internal server error"
 // @Router /subscriptions/get [get]
 func (h *SubscriptionHandler) Get(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
@@ -111,24 +161,65 @@ func (h *SubscriptionHandler) Get(ctx context.Context, w http.ResponseWriter, r
 	name := params.Get("service_name")
 
 	if userIDStr == "" || name == "" {
-		http.Error(w, "missing user_id or service_name", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "missing user_id or service_name")
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "invalid user_id",
+			problem.FieldError{Field: "user_id", Message: "must be a UUID"})
+		return
+	}
+
+	sub, ok := h.resolveByNameAndUserID(ctx, w, r, name, userID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
+// GetByID godoc
+// @Summary Get a subscription by ID
+// @Description Get subscription by its numeric identifier
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param include_deleted query bool false "Include soft-deleted subscriptions (admin)"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "subscription version, send it back in If-Match"
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id} [get]
+func (h *SubscriptionHandler) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	includeDeleted, err := optionalBool(r.URL.Query(), "include_deleted")
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	sub, err := h.Service.SelectByNameAndUserID(ctx, name, userID)
+	sub, err := h.Service.SelectByID(ctx, id, includeDeleted)
 	if err != nil {
-		log.Printf("failed to get subscription: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "get subscription")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(sub); err != nil {
 		log.Printf("failed to encode subscription to JSON: %v", err)
@@ -137,118 +228,867 @@ func (h *SubscriptionHandler) Get(ctx context.Context, w http.ResponseWriter, r
 
 // Update godoc
 // @Summary Update a subscription
-// @Description Update subscription fields
+// @Description Update subscription fields. Deprecated: use PUT /subscriptions/{id}
+// @Description Omitted currency, billing_period, interval_months, anchor_day, tags and members keep their stored value.
 // @Tags subscriptions
+// @Deprecated
 // @Accept json
 // @Produce json
 // @Param subscription body models.Subscription true "Subscription data"
+// @Param If-Match header string false "ETag of the version being replaced"
 // @Success 200 {string} string "OK"
-// @Failure 400 {string} string "invalid JSON or missing fields"
-// @Failure 500 {string} string "internal server error"
+// @Failure 400 {object} problem.Problem "invalid JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "several subscriptions match, use the ID route"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 500 {object} problem.Problem "internal server error"
 // @Router /subscriptions/update [put]
 func (h *SubscriptionHandler) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	version, ok := expectedVersion(w, r, false)
+	if !ok {
+		return
+	}
+
 	var sub models.Subscription
 
 	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
 		return
 	}
 
-	if sub.Name == "" || sub.Price <= 0 || sub.UserID == uuid.Nil || sub.StartDate == "" {
-		http.Error(w, "missing or invalid fields", http.StatusBadRequest)
+	// Ключи поиска берутся из тела, поэтому тело проверяется до поиска подписки
+	if err := h.Service.Validate(sub); err != nil {
+		writeServiceError(w, r, err, "validate subscription")
 		return
 	}
 
-	if err := h.Service.Update(ctx, sub); err != nil {
-		log.Printf("failed to update subscription: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+	found, ok := h.resolveByNameAndUserID(ctx, w, r, sub.Name, sub.UserID)
+	if !ok {
 		return
 	}
+	sub.ID = found.ID
+	sub.Version = version
+	keepLegacyFields(&sub, found)
 
+	if err := h.Service.UpdateByID(ctx, &sub); err != nil {
+		writeServiceError(w, r, err, "update subscription")
+		return
+	}
+
+	w.Header().Set("ETag", etag(sub.Version))
 	w.WriteHeader(http.StatusOK)
 }
 
+// UpdateByID godoc
+// @Summary Replace a subscription
+// @Description Replace all fields of the subscription with the given ID.
+// @Description A changed price applies from the current month; use POST /subscriptions/{id}/prices for other months.
+// @Description status is not replaced, use the activate, pause and cancel endpoints.
+// @Description Omitted tags and members keep their stored value; send an empty array to remove them.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body models.Subscription true "Subscription data"
+// @Param If-Match header string false "ETag of the current version; required unless disabled by REQUIRE_IF_MATCH=false"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "subscription conflicts with an existing one"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 428 {object} problem.Problem "If-Match header is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id} [put]
+func (h *SubscriptionHandler) UpdateByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, h.RequireIfMatch)
+	if !ok {
+		return
+	}
+
+	var sub models.Subscription
+
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	sub.ID = id
+	sub.Version = version
+
+	if err := h.Service.UpdateByID(ctx, &sub); err != nil {
+		writeServiceError(w, r, err, "update subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
 // Delete godoc
 // @Summary Delete a subscription
-// @Description Delete subscription by user_id and service_name
+// @Description Delete subscription by user_id and service_name. Deprecated: use DELETE /subscriptions/{id}
 // @Tags subscriptions
+// @Deprecated
 // @Produce json
 // @Param user_id query string true "User ID (UUID)"
-// @Param name query string true "Service Name"
+// @Param service_name query string true "Service Name"
+// @Param name query string false "Former name of service_name, accepted when service_name is absent"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {string} string "missing or invalid parameters"
-// @Failure 500 {string} string "internal server error"
+// @Failure 400 {object} problem.Problem "missing or invalid parameters"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "several subscriptions match, use the ID route"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 500 {object} problem.Problem "internal server error"
 // @Router /subscriptions/delete [delete]
 func (h *SubscriptionHandler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 
 	userIDStr := params.Get("user_id")
-	name := params.Get("name")
+	// Раньше маршрут читал name, в отличие от service_name у Get; старое имя пока принимается
+	name := params.Get("service_name")
+	if name == "" {
+		name = params.Get("name")
+	}
 
 	if userIDStr == "" || name == "" {
-		http.Error(w, "missing user_id or service_name", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "missing user_id or service_name")
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "invalid user_id",
+			problem.FieldError{Field: "user_id", Message: "must be a UUID"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, false)
+	if !ok {
+		return
+	}
+
+	sub, ok := h.resolveByNameAndUserID(ctx, w, r, name, userID)
+	if !ok {
 		return
 	}
 
-	if err := h.Service.Delete(ctx, name, userID); err != nil {
-		log.Printf("failed to delete subscription: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+	if err := h.Service.DeleteByID(ctx, sub.ID, version); err != nil {
+		writeServiceError(w, r, err, "delete subscription")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// mergePatchType — медиатип JSON Merge Patch (RFC 7396).
+const mergePatchType = "application/merge-patch+json"
+
+// Patch godoc
+// @Summary Partially update a subscription
+// @Description Apply a JSON Merge Patch (RFC 7396): only the supplied fields are changed,
+// @Description null clears end_date or interval_months. The whole resulting subscription is validated.
+// @Tags subscriptions
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param patch body models.SubscriptionPatch true "Fields to change"
+// @Param If-Match header string false "ETag of the current version; required unless disabled by REQUIRE_IF_MATCH=false"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id or malformed patch"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "subscription conflicts with an existing one"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 415 {object} problem.Problem "content type is not application/merge-patch+json"
+// @Failure 422 {object} problem.Problem "invalid fields, all violations are listed in errors"
+// @Failure 428 {object} problem.Problem "If-Match header is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id} [patch]
+func (h *SubscriptionHandler) Patch(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != mergePatchType {
+		problem.Error(w, r, http.StatusUnsupportedMediaType, "content type must be "+mergePatchType)
+		return
+	}
+
+	version, ok := expectedVersion(w, r, h.RequireIfMatch)
+	if !ok {
+		return
+	}
+
+	var patch models.SubscriptionPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid merge patch: "+err.Error())
+		return
+	}
+
+	sub, err := h.Service.Patch(ctx, id, version, patch)
+	if err != nil {
+		writeServiceError(w, r, err, "patch subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
+// DeleteByID godoc
+// @Summary Delete a subscription by ID
+// @Description Soft-delete subscription by its numeric identifier. The row is kept for billing history,
+// @Description hidden from queries and purged after the retention period; see POST /subscriptions/{id}/restore
+// @Tags subscriptions
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the current version; required unless disabled by REQUIRE_IF_MATCH=false"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 428 {object} problem.Problem "If-Match header is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id} [delete]
+func (h *SubscriptionHandler) DeleteByID(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, h.RequireIfMatch)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteByID(ctx, id, version); err != nil {
+		writeServiceError(w, r, err, "delete subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Restore godoc
+// @Summary Restore a deleted subscription
+// @Description Undo the soft delete of the subscription with the given ID
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the deleted version"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found or already purged"
+// @Failure 409 {object} problem.Problem "subscription is not deleted"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) Restore(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, false)
+	if !ok {
+		return
+	}
+
+	sub, err := h.Service.RestoreByID(ctx, id, version)
+	if err != nil {
+		writeServiceError(w, r, err, "restore subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
+// History godoc
+// @Summary Subscription change history
+// @Description Audit log of all changes of the subscription in chronological order, with the state before
+// @Description and after each change. The actor is taken from the X-Actor header of the changing request.
+// @Description The history stays available after the subscription is purged. Subscriptions created before
+// @Description the audit log was introduced may have an empty history.
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found and has no history"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/history [get]
+func (h *SubscriptionHandler) History(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	entries, err := h.Service.History(ctx, id)
+	if err != nil {
+		writeServiceError(w, r, err, "get subscription history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// Prices godoc
+// @Summary Subscription price history
+// @Description Price periods of the subscription: each price applies from its month until the next period starts
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} models.PricePeriod
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/prices [get]
+func (h *SubscriptionHandler) Prices(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	prices, err := h.Service.Prices(ctx, id)
+	if err != nil {
+		writeServiceError(w, r, err, "list subscription prices")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(prices); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// AddPrice godoc
+// @Summary Change subscription price
+// @Description Start a new price period. Months before effective_from keep their price, so past sums do not change.
+// @Description effective_from may be in the past but not after the current month (or the start of a future subscription).
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the current version"
+// @Param price body models.PricePeriod true "New price and the month it applies from"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id or JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid price period"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/prices [post]
+func (h *SubscriptionHandler) AddPrice(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, false)
+	if !ok {
+		return
+	}
+
+	var p models.PricePeriod
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	sub, err := h.Service.AddPrice(ctx, id, version, p)
+	if err != nil {
+		writeServiceError(w, r, err, "change subscription price")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
+// Statuses godoc
+// @Summary Subscription status history
+// @Description Status periods of the subscription: each status applies from its month until the next period starts.
+// @Description Charges are billed only in active months.
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} models.StatusPeriod
+// @Failure 400 {object} problem.Problem "invalid id"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/statuses [get]
+func (h *SubscriptionHandler) Statuses(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	statuses, err := h.Service.Statuses(ctx, id)
+	if err != nil {
+		writeServiceError(w, r, err, "list subscription statuses")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// StatusChangeRequest is the optional body of the status transition endpoints
+type StatusChangeRequest struct {
+	// Month the new status applies from, defaults to the current month (or the start of a future subscription).
+	// May be in the past, but not before the start of the current status.
+	EffectiveFrom models.YearMonth `json:"effective_from" swaggertype:"string" example:"2025-12"`
+}
+
+// Activate godoc
+// @Summary Activate a subscription
+// @Description End the trial or resume a paused subscription (trial → active, paused → active)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the current version"
+// @Param change body StatusChangeRequest false "Month the transition applies from"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id or JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "transition is not allowed from the current status"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid effective_from"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/activate [post]
+func (h *SubscriptionHandler) Activate(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	h.transition(ctx, w, r, models.StatusActive)
+}
+
+// Pause godoc
+// @Summary Pause a subscription
+// @Description Stop charging an active subscription until it is activated again (active → paused)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the current version"
+// @Param change body StatusChangeRequest false "Month the transition applies from"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id or JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "transition is not allowed from the current status"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid effective_from"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) Pause(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	h.transition(ctx, w, r, models.StatusPaused)
+}
+
+// Cancel godoc
+// @Summary Cancel a subscription
+// @Description Stop charging the subscription for good; a cancelled subscription cannot change its status anymore
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the current version"
+// @Param change body StatusChangeRequest false "Month the transition applies from"
+// @Success 200 {object} models.Subscription
+// @Header 200 {string} ETag "new subscription version"
+// @Failure 400 {object} problem.Problem "invalid id or JSON"
+// @Failure 404 {object} problem.Problem "subscription not found"
+// @Failure 409 {object} problem.Problem "subscription is already cancelled"
+// @Failure 412 {object} problem.Problem "subscription was changed since the ETag was issued"
+// @Failure 422 {object} problem.Problem "invalid effective_from"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) Cancel(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	h.transition(ctx, w, r, models.StatusCancelled)
+}
+
+// transition переводит подписку из пути запроса в состояние status. Тело запроса необязательно.
+func (h *SubscriptionHandler) transition(ctx context.Context, w http.ResponseWriter, r *http.Request, status string) {
+	id, err := pathID(r)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "invalid id",
+			problem.FieldError{Field: "id", Message: "must be a positive integer"})
+		return
+	}
+
+	version, ok := expectedVersion(w, r, false)
+	if !ok {
+		return
+	}
+
+	var req StatusChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem.Error(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	sub, err := h.Service.Transition(ctx, id, version, models.StatusPeriod{Status: status, EffectiveFrom: req.EffectiveFrom})
+	if err != nil {
+		writeServiceError(w, r, err, "change subscription status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("failed to encode subscription to JSON: %v", err)
+	}
+}
+
+// SumPriceResponse is the result of the period cost calculation
+type SumPriceResponse struct {
+	TotalPrice int                       `json:"total price" example:"300"`
+	Currency   string                    `json:"currency" example:"RUB"`
+	Details    []models.SubscriptionCost `json:"details,omitempty"`
+	Groups     []models.CostGroup        `json:"groups,omitempty"`
+}
+
 // SumPrice godoc
 // @Summary Sum subscription prices
-// @Description Calculate total subscription cost over a period, filtered by user_id and service_name
+// @Description Calculate total subscription cost over a period: every charge a subscription's billing period places within [start_date, end_date] while the subscription is active (trial, paused and cancelled months are skipped) is priced at the subscription price effective on the charge date and converted to the requested currency at the rate valid on that date. Filtered by user_id and service_name
 // @Tags subscriptions
 // @Produce json
-// @Param user_id query string false "User ID (UUID)"
-// @Param service_name query string false "Service Name"
-// @Param start_date query string false "Start date YYYY-MM"
-// @Param end_date query string false "End date YYYY-MM"
-// @Success 200 {object} map[string]int "Total price"
-// @Failure 400 {string} string "invalid parameters"
-// @Failure 500 {string} string "internal server error"
+// @Param user_id query string false "User ID (UUID); counts only this user's share of the subscriptions they pay for"
+// @Param service_name query string false "Service name or alias from the catalog"
+// @Param start_date query string true "Start month YYYY-MM or MM-YYYY"
+// @Param end_date query string false "End month YYYY-MM or MM-YYYY, defaults to the current month"
+// @Param currency query string false "Target currency code" default(RUB)
+// @Param detailed query bool false "Include per-subscription breakdown; the total does not depend on it, and rounded per-subscription costs may differ from it by rounding"
+// @Param group_by query string false "Add totals per service category or per tag; a charge of a subscription with several tags counts in each of them" Enums(category, tag)
+// @Param category query string false "Category of the catalog service, case-insensitive"
+// @Param tags query string false "Comma-separated tags, the subscription must have all of them"
+// @Param include_deleted query bool false "Include soft-deleted subscriptions (admin)"
+// @Success 200 {object} SumPriceResponse
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 422 {object} problem.Problem "exchange rate is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
 // @Router /subscriptions/sum [get]
 func (h *SubscriptionHandler) SumPrice(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 
-	var userID uuid.UUID
-	var err error
+	q, err := parseCostQuery(params)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	userIDStr := params.Get("user_id")
-	name := params.Get("service_name")
-	startDate := params.Get("start_date")
-	endDate := params.Get("end_date")
+	detailed := false
+	if detailedStr := params.Get("detailed"); detailedStr != "" {
+		detailed, err = strconv.ParseBool(detailedStr)
+		if err != nil {
+			problem.Error(w, r, http.StatusBadRequest, "invalid detailed value")
+			return
+		}
+	}
+
+	groupBy := params.Get("group_by")
+	if groupBy != "" && groupBy != models.GroupByCategory && groupBy != models.GroupByTag {
+		problem.Error(w, r, http.StatusBadRequest, "invalid group_by value",
+			problem.FieldError{Field: "group_by", Message: "must be category or tag"})
+		return
+	}
 
-	if userIDStr != "" {
-		userID, err = uuid.Parse(userIDStr)
+	resp := SumPriceResponse{Currency: q.Currency}
+	if groupBy != "" {
+		resp.Groups, err = h.Service.SumPriceGroups(ctx, q, groupBy)
 		if err != nil {
-			http.Error(w, "invalid user_id", http.StatusBadRequest)
+			writeCostError(w, r, err)
 			return
 		}
 	}
 
-	sum, err := h.Service.SumPrice(ctx, name, userID, startDate, endDate)
+	if detailed {
+		resp.Details, resp.TotalPrice, err = h.Service.SumPriceDetails(ctx, q)
+	} else {
+		resp.TotalPrice, err = h.Service.SumPrice(ctx, q)
+	}
 	if err != nil {
-		log.Printf("failed to sum subscriptions: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeCostError(w, r, err)
 		return
 	}
 
-	resp := map[string]int{"total price": sum}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		log.Printf("failed to encode JSON: %v", err)
 	}
 }
+
+// writeCostError отвечает на ошибку расчёта стоимости: нехватка курса валюты — 422.
+func writeCostError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, models.ErrMissingExchangeRate) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeServiceError(w, r, err, "sum subscriptions")
+}
+
+// Analytics godoc
+// @Summary Spending analytics
+// @Description Totals of subscription charges over a period grouped by month, by service name and by user (each member gets their share of shared subscriptions), computed with the same rules as /subscriptions/sum
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "User ID (UUID); counts only this user's share of the subscriptions they pay for"
+// @Param service_name query string false "Service name or alias from the catalog"
+// @Param start_date query string true "Start month YYYY-MM or MM-YYYY"
+// @Param end_date query string false "End month YYYY-MM or MM-YYYY, defaults to the current month"
+// @Param currency query string false "Target currency code" default(RUB)
+// @Param category query string false "Category of the catalog service, case-insensitive"
+// @Param tags query string false "Comma-separated tags, the subscription must have all of them"
+// @Param include_deleted query bool false "Include soft-deleted subscriptions (admin)"
+// @Success 200 {object} models.Analytics
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 422 {object} problem.Problem "exchange rate is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /subscriptions/analytics [get]
+func (h *SubscriptionHandler) Analytics(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	q, err := parseCostQuery(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a, err := h.Service.Analytics(ctx, q)
+	if errors.Is(err, models.ErrMissingExchangeRate) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, err, "compute analytics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(a); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// maxChargesRange ограничивает окно развёртки, чтобы еженедельные подписки не давали огромных ответов.
+const maxChargesRange = 2 * 366 * 24 * time.Hour
+
+// Charges godoc
+// @Summary List charges of a user
+// @Description Expand every subscription the user pays for (as its member, or as the owner of an unshared one) into dated charges with the user's share of the amount within [from, to] according to its billing period, skipping months the subscription is not active in
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User ID (UUID)"
+// @Param from query string false "First day YYYY-MM-DD, defaults to today"
+// @Param to query string false "Last day YYYY-MM-DD, defaults to a month after from"
+// @Param currency query string false "Convert amounts to this currency; by default amounts stay in the subscription currency"
+// @Param include_deleted query bool false "Include soft-deleted subscriptions (admin)"
+// @Success 200 {array} models.Charge
+// @Failure 400 {object} problem.Problem "invalid parameters"
+// @Failure 422 {object} problem.Problem "exchange rate is missing"
+// @Failure 500 {object} problem.Problem "internal server error"
+// @Router /users/{user_id}/charges [get]
+func (h *SubscriptionHandler) Charges(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var err error
+	q := models.ChargeQuery{Currency: params.Get("currency")}
+
+	// Нулевой UUID снял бы фильтр по пользователю и вернул бы списания всех пользователей
+	var ok bool
+	if q.UserID, ok = pathUserID(w, r); !ok {
+		return
+	}
+
+	if fromStr := params.Get("from"); fromStr != "" {
+		if q.From, err = models.ParseDate(fromStr); err != nil {
+			problem.Error(w, r, http.StatusBadRequest, "from: "+err.Error())
+			return
+		}
+	}
+
+	if toStr := params.Get("to"); toStr != "" {
+		if q.To, err = models.ParseDate(toStr); err != nil {
+			problem.Error(w, r, http.StatusBadRequest, "to: "+err.Error())
+			return
+		}
+		if q.From.IsZero() {
+			problem.Error(w, r, http.StatusBadRequest, "to requires from")
+			return
+		}
+		if q.To.Before(q.From.Time) {
+			problem.Error(w, r, http.StatusBadRequest, "to must not be before from")
+			return
+		}
+		if q.To.Sub(q.From.Time) > maxChargesRange {
+			problem.Error(w, r, http.StatusBadRequest, "range between from and to is too large")
+			return
+		}
+	}
+
+	if q.Currency != "" && !models.IsSupportedCurrency(models.NormalizeCurrency(q.Currency)) {
+		problem.Error(w, r, http.StatusBadRequest, "unsupported currency",
+			problem.FieldError{Field: "currency", Message: "must be one of RUB, USD, EUR"})
+		return
+	}
+
+	if q.IncludeDeleted, err = optionalBool(params, "include_deleted"); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	charges, err := h.Service.Charges(ctx, q)
+	if errors.Is(err, models.ErrMissingExchangeRate) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, err, "list charges")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(charges); err != nil {
+		log.Printf("failed to encode JSON: %v", err)
+	}
+}
+
+// parseCostQuery разбирает общие параметры расчёта стоимости за период.
+func parseCostQuery(params url.Values) (models.CostQuery, error) {
+	var err error
+	q := models.CostQuery{
+		Name:     params.Get("service_name"),
+		Currency: models.NormalizeCurrency(params.Get("currency")),
+		Category: params.Get("category"),
+		Tags:     tagList(params, "tags"),
+	}
+
+	if userIDStr := params.Get("user_id"); userIDStr != "" {
+		q.UserID, err = uuid.Parse(userIDStr)
+		if err != nil {
+			return q, errors.New("invalid user_id")
+		}
+	}
+
+	q.StartDate, err = models.ParseYearMonth(params.Get("start_date"))
+	if err != nil {
+		return q, fmt.Errorf("start_date: %w", err)
+	}
+
+	if endDateStr := params.Get("end_date"); endDateStr != "" {
+		q.EndDate, err = models.ParseYearMonth(endDateStr)
+		if err != nil {
+			return q, fmt.Errorf("end_date: %w", err)
+		}
+		if q.EndDate.Before(q.StartDate) {
+			return q, errors.New("end_date must not be before start_date")
+		}
+	}
+
+	if !models.IsSupportedCurrency(q.Currency) {
+		return q, errors.New("unsupported currency")
+	}
+
+	if q.IncludeDeleted, err = optionalBool(params, "include_deleted"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// resolveByNameAndUserID ищет единственную подписку по имени и пользователю для устаревших маршрутов.
+// При неудаче ответ уже записан в w, и вызывающий должен просто выйти.
+func (h *SubscriptionHandler) resolveByNameAndUserID(ctx context.Context, w http.ResponseWriter, r *http.Request, name string, userID uuid.UUID) (models.Subscription, bool) {
+	sub, err := h.Service.ResolveByNameAndUserID(ctx, name, userID)
+	if errors.Is(err, service.ErrAmbiguousSubscription) {
+		problem.Error(w, r, http.StatusConflict, "several subscriptions match, use the ID route")
+		return models.Subscription{}, false
+	}
+	if err != nil {
+		writeServiceError(w, r, err, "resolve subscription")
+		return models.Subscription{}, false
+	}
+	return sub, true
+}
+
+// keepLegacyFields переносит в тело устаревшего PUT /subscriptions/update сохранённые значения
+// полей, которых в теле нет. Раньше этот маршрут менял только цену и даты, и старые клиенты
+// не знают о новых полях: значения по умолчанию молча изменили бы стоимость подписки.
+func keepLegacyFields(sub *models.Subscription, stored models.Subscription) {
+	if sub.Currency == "" {
+		sub.Currency = stored.Currency
+	}
+	// Без периода оплаты годовой план стал бы ежемесячным; день списания зависит от периода
+	// и переносится, только если период не меняется
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = stored.BillingPeriod
+		if sub.IntervalMonths == nil {
+			sub.IntervalMonths = stored.IntervalMonths
+		}
+	}
+	if sub.AnchorDay == 0 && sub.BillingPeriod == stored.BillingPeriod {
+		sub.AnchorDay = stored.AnchorDay
+	}
+}
+
+// pathID извлекает числовой идентификатор подписки из пути запроса.
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/problem"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestChargesRejectsInvalidUserID(t *testing.T) {
+	// Сервис не задан: запрос должен быть отклонён до обращения к нему
+	h := &SubscriptionHandler{}
+
+	for _, userID := range []string{
+		"00000000-0000-0000-0000-000000000000",
+		"not-a-uuid",
+	} {
+		t.Run(userID, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userID+"/charges", nil)
+			r.SetPathValue("user_id", userID)
+			w := httptest.NewRecorder()
+
+			h.Charges(r.Context(), w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Fatalf("Content-Type = %q, want %q", ct, problem.ContentType)
+			}
+			var p problem.Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if len(p.Errors) != 1 || p.Errors[0].Field != "user_id" {
+				t.Fatalf("errors = %+v, want a single user_id violation", p.Errors)
+			}
+		})
+	}
+}
+
+func TestKeepLegacyFields(t *testing.T) {
+	interval := 6
+	yearly := models.Subscription{
+		Currency:      "USD",
+		BillingPeriod: models.BillingYearly,
+		AnchorDay:     15,
+	}
+	custom := models.Subscription{
+		Currency:       "EUR",
+		BillingPeriod:  models.BillingCustom,
+		IntervalMonths: &interval,
+		AnchorDay:      10,
+	}
+
+	tests := []struct {
+		name   string
+		body   models.Subscription
+		stored models.Subscription
+		want   models.Subscription
+	}{
+		{
+			name:   "omitted fields keep the stored plan",
+			body:   models.Subscription{Price: 1200},
+			stored: yearly,
+			want:   models.Subscription{Price: 1200, Currency: "USD", BillingPeriod: models.BillingYearly, AnchorDay: 15},
+		},
+		{
+			name:   "custom interval is kept with the period",
+			body:   models.Subscription{},
+			stored: custom,
+			want:   custom,
+		},
+		{
+			name:   "given fields win",
+			body:   models.Subscription{Currency: "RUB", BillingPeriod: models.BillingYearly, AnchorDay: 3},
+			stored: yearly,
+			want:   models.Subscription{Currency: "RUB", BillingPeriod: models.BillingYearly, AnchorDay: 3},
+		},
+		{
+			name:   "anchor day is not carried over to another period",
+			body:   models.Subscription{BillingPeriod: models.BillingWeekly},
+			stored: yearly,
+			want:   models.Subscription{Currency: "USD", BillingPeriod: models.BillingWeekly},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.body
+			keepLegacyFields(&got, tt.stored)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("keepLegacyFields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
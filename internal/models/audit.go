@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Действия, которые попадают в журнал изменений подписок.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// SystemActor записывается в журнал, когда изменение сделано не по запросу клиента
+// (например, фоновой очисткой) или клиент не представился.
+const SystemActor = "system"
+
+// AuditEntry — запись журнала изменений подписки.
+type AuditEntry struct {
+	ID             int64         `json:"id" example:"1"`
+	SubscriptionID int           `json:"subscription_id" example:"1"`
+	Action         string        `json:"action" enums:"create,update,delete,restore,purge" example:"update"`
+	Before         *Subscription `json:"before,omitempty"` // absent for create
+	After          *Subscription `json:"after,omitempty"`  // absent for purge
+	Actor          string        `json:"actor" example:"admin@example.com"`
+	RequestID      string        `json:"request_id,omitempty" example:"3f0b8c2e-5a61-4c1e-9d7a-2b6f1e0c9a14"`
+	ChangedAt      time.Time     `json:"changed_at" example:"2025-11-01T12:00:00Z"`
+}
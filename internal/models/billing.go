@@ -0,0 +1,23 @@
+package models
+
+// Периоды оплаты подписки. Цена подписки указывается за один период.
+const (
+	BillingWeekly    = "weekly"
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+	BillingCustom    = "custom" // каждые IntervalMonths месяцев
+)
+
+// NormalizeBilling подставляет значения по умолчанию: ежемесячная оплата первого числа.
+func (s *Subscription) NormalizeBilling() {
+	if s.BillingPeriod == "" {
+		s.BillingPeriod = BillingMonthly
+	}
+	if s.AnchorDay == 0 {
+		s.AnchorDay = 1
+	}
+	if s.BillingPeriod != BillingCustom {
+		s.IntervalMonths = nil
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget — месячный лимит расходов пользователя на подписки: общий, на категорию сервисов
+// или на один сервис каталога. Category и ServiceID взаимоисключающие.
+type Budget struct {
+	ID        int       `json:"id" example:"1"`
+	UserID    uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Category  *string   `json:"category,omitempty" example:"music"` // limit only subscriptions of this service category
+	ServiceID *int      `json:"service_id,omitempty" example:"3"`   // limit only subscriptions of this catalog service
+	Limit     int       `json:"monthly_limit" example:"1500"`
+	Currency  string    `json:"currency" example:"RUB"`
+}
+
+// BudgetMonth — прогноз расходов в рамках бюджета на один месяц: все списания месяца,
+// включая ещё не наступившие, в валюте бюджета.
+type BudgetMonth struct {
+	Month      YearMonth `json:"month" swaggertype:"string" example:"2025-11"`
+	Projected  int       `json:"projected" example:"1799"`
+	Limit      int       `json:"monthly_limit" example:"1500"`
+	Remaining  int       `json:"remaining" example:"-299"` // negative when over budget
+	OverBudget bool      `json:"over_budget" example:"true"`
+}
+
+// BudgetStatus — состояние бюджета в текущем и следующем месяцах.
+type BudgetStatus struct {
+	Budget Budget        `json:"budget"`
+	Months []BudgetMonth `json:"months"`
+}
+
+// Виды событий бюджета.
+const (
+	BudgetExceeded  = "exceeded"
+	BudgetRecovered = "recovered"
+)
+
+// BudgetEvent — переход бюджета через лимит в одном месяце. События пишутся в журнал
+// с возрастающим ID, потребители читают их после последнего обработанного ID.
+type BudgetEvent struct {
+	ID        int64     `json:"id" example:"42"`
+	BudgetID  int       `json:"budget_id" example:"1"`
+	UserID    uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Month     YearMonth `json:"month" swaggertype:"string" example:"2025-11"`
+	Kind      string    `json:"kind" enums:"exceeded,recovered" example:"exceeded"`
+	Projected int       `json:"projected" example:"1799"`
+	Limit     int       `json:"monthly_limit" example:"1500"`
+	Currency  string    `json:"currency" example:"RUB"`
+	CreatedAt time.Time `json:"created_at"`
+}
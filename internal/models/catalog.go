@@ -0,0 +1,13 @@
+package models
+
+// Service — сервис из каталога. Подписка ссылается на сервис по service_id, а её название
+// при создании и изменении ищется среди названий и псевдонимов сервисов без учёта регистра
+// и заменяется каноническим названием сервиса.
+type Service struct {
+	ID           int      `json:"id" example:"1"`
+	Name         string   `json:"name" example:"Yandex Plus"`                // canonical name
+	Aliases      []string `json:"aliases" example:"yandex plus,Яндекс Плюс"` // alternative names resolved to this service
+	Category     *string  `json:"category,omitempty" example:"music"`
+	DefaultPrice *int     `json:"default_price,omitempty" example:"399"`
+	Currency     string   `json:"currency" example:"RUB"` // currency of default_price
+}
@@ -0,0 +1,109 @@
+package models
+
+import "github.com/google/uuid"
+
+// CostQuery — параметры расчёта стоимости подписок за период.
+type CostQuery struct {
+	UserID    uuid.UUID // uuid.Nil — все пользователи
+	Name      string    // название или псевдоним сервиса; пустая строка — все сервисы
+	StartDate YearMonth
+	EndDate   YearMonth
+	Currency  string   // валюта, в которую пересчитываются цены
+	Category  string   // категория сервиса; пустая строка — все категории
+	ServiceID int      // сервис каталога; 0 — все сервисы
+	Tags      []string // подписка помечена всеми этими тегами
+
+	IncludeDeleted bool // учитывать мягко удалённые подписки
+}
+
+// Charges возвращает запрос списаний с первого дня StartDate по последний день EndDate.
+func (q CostQuery) Charges() ChargeQuery {
+	return ChargeQuery{
+		UserID:    q.UserID,
+		Name:      q.Name,
+		From:      q.StartDate.FirstDay(),
+		To:        q.EndDate.LastDay(),
+		Currency:  q.Currency,
+		Category:  q.Category,
+		ServiceID: q.ServiceID,
+		Tags:      q.Tags,
+
+		IncludeDeleted: q.IncludeDeleted,
+	}
+}
+
+// ChargeQuery — параметры развёртки подписок в отдельные списания за [From, To].
+type ChargeQuery struct {
+	UserID    uuid.UUID // uuid.Nil — все пользователи
+	Name      string    // название или псевдоним сервиса; пустая строка — все сервисы
+	From      Date
+	To        Date
+	Currency  string   // пустая строка — суммы в валюте подписки
+	Category  string   // категория сервиса; пустая строка — все категории
+	ServiceID int      // сервис каталога; 0 — все сервисы
+	Tags      []string // подписка помечена всеми этими тегами
+
+	IncludeDeleted bool // учитывать мягко удалённые подписки
+}
+
+// Charge — одно списание по подписке.
+type Charge struct {
+	SubscriptionID int       `json:"subscription_id" example:"1"`
+	Name           string    `json:"name" example:"Premium"`
+	UserID         uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Date           Date      `json:"date" swaggertype:"string" example:"2025-11-01"`
+	Amount         int       `json:"amount" example:"100"`
+	Currency       string    `json:"currency" example:"RUB"`
+}
+
+// SubscriptionCost — стоимость одной подписки за запрошенный период.
+// Price указан в валюте подписки Currency, Cost — в валюте запроса.
+type SubscriptionCost struct {
+	SubscriptionID int       `json:"subscription_id" example:"1"`
+	Name           string    `json:"name" example:"Premium"`
+	UserID         uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Price          int       `json:"price" example:"100"`
+	Currency       string    `json:"currency" example:"USD"`
+	Charges        int       `json:"charges" example:"3"`
+	Cost           int       `json:"cost" example:"300"`
+}
+
+// Analytics — разбивка стоимости подписок за период по месяцам, сервисам и пользователям.
+type Analytics struct {
+	Currency  string         `json:"currency" example:"RUB"`
+	Total     int            `json:"total" example:"1200"`
+	ByMonth   []MonthTotal   `json:"by_month"`
+	ByService []ServiceTotal `json:"by_service"`
+	ByUser    []UserTotal    `json:"by_user"`
+}
+
+type MonthTotal struct {
+	Month   YearMonth `json:"month" swaggertype:"string" example:"2025-11"`
+	Total   int       `json:"total" example:"400"`
+	Charges int       `json:"charges" example:"4"`
+}
+
+type ServiceTotal struct {
+	Name    string `json:"name" example:"Premium"`
+	Total   int    `json:"total" example:"600"`
+	Charges int    `json:"charges" example:"6"`
+}
+
+// Группировки стоимости подписок в SumPriceGroups.
+const (
+	GroupByCategory = "category"
+	GroupByTag      = "tag"
+)
+
+// CostGroup — стоимость подписок одной категории сервиса или одного тега за период.
+type CostGroup struct {
+	Key     *string `json:"key" example:"music"` // null for subscriptions without a category (tags)
+	Total   int     `json:"total" example:"600"`
+	Charges int     `json:"charges" example:"6"`
+}
+
+type UserTotal struct {
+	UserID  uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Total   int       `json:"total" example:"1200"`
+	Charges int       `json:"charges" example:"12"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// BaseCurrency — валюта, к которой приводятся курсы в currency_rates.
+const BaseCurrency = "RUB"
+
+var supportedCurrencies = map[string]bool{
+	"RUB": true,
+	"USD": true,
+	"EUR": true,
+}
+
+// ErrMissingExchangeRate возвращается, когда для пересчёта не найден курс на нужную дату.
+var ErrMissingExchangeRate = errors.New("exchange rate is missing for a billed month")
+
+// NormalizeCurrency приводит код валюты к верхнему регистру, пустой код заменяется базовой валютой.
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return BaseCurrency
+	}
+	return code
+}
+
+func IsSupportedCurrency(code string) bool {
+	return supportedCurrencies[code]
+}
+
+// CurrencyRate — стоимость одной единицы валюты в BaseCurrency, действующая с даты Date.
+type CurrencyRate struct {
+	Currency string  `json:"currency" example:"USD"`
+	Date     Date    `json:"date" swaggertype:"string" example:"2025-11-01"`
+	Rate     float64 `json:"rate" example:"92.5"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date — календарная дата без времени. В JSON передаётся как "YYYY-MM-DD",
+// в PostgreSQL хранится в колонке DATE.
+type Date struct {
+	time.Time
+}
+
+// ParseDate разбирает строку формата "YYYY-MM-DD".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, must be YYYY-MM-DD", s)
+	}
+	return Date{Time: t}, nil
+}
+
+// DateOf отбрасывает время и часовой пояс у t.
+func DateOf(t time.Time) Date {
+	return Date{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// ScanDate реализует pgtype.DateScanner для чтения колонки DATE.
+func (d *Date) ScanDate(v pgtype.Date) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into Date")
+	}
+	if v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("cannot scan infinite date into Date")
+	}
+
+	*d = DateOf(v.Time)
+	return nil
+}
+
+// DateValue реализует pgtype.DateValuer для записи в колонку DATE.
+func (d Date) DateValue() (pgtype.Date, error) {
+	return pgtype.Date{Time: d.Time, Valid: true}, nil
+}
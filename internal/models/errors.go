@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ошибки предметной области. Репозиторий и сервис оборачивают их через fmt.Errorf("%w: ..."),
+// обработчики по errors.Is выбирают HTTP-статус.
+var (
+	// ErrNotFound — запись с указанными идентификатором или ключом не существует.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict — запись противоречит уже существующим (уникальность, ссылки, неоднозначный ключ).
+	ErrConflict = errors.New("conflict")
+	// ErrValidation — данные не прошли проверки, в том числе ограничения базы данных.
+	ErrValidation = errors.New("validation failed")
+	// ErrStaleVersion — запись изменилась после того, как клиент получил её версию.
+	ErrStaleVersion = errors.New("version does not match")
+)
+
+// FieldViolation — нарушение правила проверки для одного поля.
+type FieldViolation struct {
+	Field   string
+	Message string
+}
+
+// ValidationError содержит все нарушения, найденные при проверке, и сопоставляется с ErrValidation.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SortableFields — поля, по которым разрешена сортировка списка подписок.
+var SortableFields = map[string]bool{
+	"id":         true,
+	"name":       true,
+	"price":      true,
+	"user_id":    true,
+	"start_date": true,
+	"end_date":   true,
+}
+
+// SortField — поле сортировки и её направление.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort разбирает список полей через запятую; минус перед полем означает сортировку по убыванию,
+// например "-price,name".
+func ParseSort(s string) ([]SortField, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		f := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !SortableFields[f.Field] {
+			return nil, fmt.Errorf("unsupported sort field %q", f.Field)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// SubscriptionFilter — условия отбора, сортировка и пагинация списка подписок.
+// Нулевые значения полей означают отсутствие условия.
+type SubscriptionFilter struct {
+	UserID     uuid.UUID
+	Name       string // название или псевдоним сервиса из каталога
+	NamePrefix string // префикс без учёта регистра
+	Status     string
+	Category   string   // категория сервиса из каталога
+	Tags       []string // подписка помечена всеми этими тегами
+	PriceMin   *int
+	PriceMax   *int
+	ActiveOn   *YearMonth // подписка действует в этом месяце
+	StartFrom  *YearMonth
+	StartTo    *YearMonth
+	EndFrom    *YearMonth
+	EndTo      *YearMonth
+	Sort       []SortField
+
+	// IncludeDeleted добавляет к выборке мягко удалённые подписки
+	IncludeDeleted bool
+
+	Limit  int
+	Offset int
+	After  *PageCursor // keyset-пагинация: строки после этой позиции
+}
+
+// FormatSort — обратная к ParseSort операция, используется для привязки курсора к сортировке.
+func FormatSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ErrInvalidCursor возвращается для повреждённого курсора или курсора от другой сортировки.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageCursor — позиция последней отданной строки: значения полей сортировки и id.
+// Значение NULL в Values не хранится.
+type PageCursor struct {
+	Sort   string            `json:"s,omitempty"`
+	Values map[string]string `json:"v,omitempty"`
+	ID     int               `json:"id"`
+}
+
+// CursorAfter возвращает курсор, указывающий на подписку s при сортировке sort.
+func CursorAfter(s Subscription, sort []SortField) PageCursor {
+	c := PageCursor{Sort: FormatSort(sort), Values: map[string]string{}, ID: s.ID}
+	for _, f := range sort {
+		if v, ok := s.sortValue(f.Field); ok {
+			c.Values[f.Field] = v
+		}
+	}
+	return c
+}
+
+func (s Subscription) sortValue(field string) (string, bool) {
+	switch field {
+	case "id":
+		return strconv.Itoa(s.ID), true
+	case "name":
+		return s.Name, true
+	case "price":
+		return strconv.Itoa(s.Price), true
+	case "user_id":
+		return s.UserID.String(), true
+	case "start_date":
+		return s.StartDate.String(), true
+	case "end_date":
+		if s.EndDate == nil {
+			return "", false
+		}
+		return s.EndDate.String(), true
+	}
+	return "", false
+}
+
+// Encode упаковывает курсор в непрозрачную для клиента строку.
+func (c PageCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную из Encode, и проверяет, что курсор выдан для той же сортировки.
+func DecodeCursor(s string, sort []SortField) (PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return PageCursor{}, ErrInvalidCursor
+	}
+
+	var c PageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	if c.Sort != FormatSort(sort) {
+		return PageCursor{}, fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// SubscriptionPage — страница списка подписок при keyset-пагинации.
+type SubscriptionPage struct {
+	Items      []Subscription `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty" example:"eyJpZCI6MTB9"` // empty on the last page
+	Total      *int           `json:"total,omitempty" example:"42"`                 // only when include_total=true
+}
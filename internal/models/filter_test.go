@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	end := YearMonth{Year: 2025, Month: 3}
+	sub := Subscription{
+		ID:        42,
+		Name:      "Netflix",
+		Price:     300,
+		UserID:    uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		StartDate: YearMonth{Year: 2024, Month: 11},
+		EndDate:   &end,
+	}
+	open := sub
+	open.EndDate = nil
+
+	tests := []struct {
+		name   string
+		sort   string
+		sub    Subscription
+		values map[string]string
+	}{
+		{"default sort", "", sub, map[string]string{}},
+		{"single field", "name", sub, map[string]string{"name": "Netflix"}},
+		{"desc and asc", "-price,name", sub, map[string]string{"price": "300", "name": "Netflix"}},
+		{"dates and uuid", "start_date,-end_date,user_id", sub, map[string]string{
+			"start_date": "2024-11",
+			"end_date":   "2025-03",
+			"user_id":    "11111111-1111-1111-1111-111111111111",
+		}},
+		{"null value is not stored", "end_date", open, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.sort, err)
+			}
+
+			c := CursorAfter(tt.sub, sort)
+			if c.ID != tt.sub.ID || c.Sort != tt.sort {
+				t.Fatalf("CursorAfter = {Sort: %q, ID: %d}, want {Sort: %q, ID: %d}", c.Sort, c.ID, tt.sort, tt.sub.ID)
+			}
+			if !reflect.DeepEqual(c.Values, tt.values) {
+				t.Fatalf("CursorAfter values = %v, want %v", c.Values, tt.values)
+			}
+
+			decoded, err := DecodeCursor(c.Encode(), sort)
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if decoded.ID != c.ID || decoded.Sort != c.Sort || len(decoded.Values) != len(c.Values) {
+				t.Fatalf("DecodeCursor = %+v, want %+v", decoded, c)
+			}
+			for k, v := range c.Values {
+				if decoded.Values[k] != v {
+					t.Fatalf("DecodeCursor value %s = %q, want %q", k, decoded.Values[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	byName, _ := ParseSort("name")
+	byNameDesc, _ := ParseSort("-name")
+	issued := PageCursor{Sort: "name", Values: map[string]string{"name": "a"}, ID: 5}.Encode()
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   []SortField
+	}{
+		{"another field", issued, nil},
+		{"another direction", issued, byNameDesc},
+		{"not base64", "%%%", byName},
+		{"not json", "bm90IGpzb24", byName},
+		{"missing id", PageCursor{Sort: "name"}.Encode(), byName},
+		{"negative id", PageCursor{Sort: "name", ID: -1}.Encode(), byName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("DecodeCursor error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []SortField
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "-price, name", want: []SortField{{Field: "price", Desc: true}, {Field: "name"}}},
+		{in: "created_at", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSort(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseSort(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("ParseSort(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
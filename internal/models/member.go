@@ -0,0 +1,10 @@
+package models
+
+import "github.com/google/uuid"
+
+// Member — участник совместной подписки. Участник оплачивает долю Share / (сумма Share всех
+// участников) каждого списания. Подписка без участников целиком оплачивается владельцем user_id.
+type Member struct {
+	UserID uuid.UUID `json:"user_id" example:"22222222-2222-2222-2222-222222222222"`
+	Share  int       `json:"share" example:"1"` // share parts, e.g. 2, 1 and 1 split a charge as 50%, 25% and 25%
+}
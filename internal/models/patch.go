@@ -0,0 +1,140 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionPatch — частичное изменение подписки в формате JSON Merge Patch (RFC 7396).
+// Отсутствующее в документе поле не меняется, null сбрасывает необязательные поля
+// end_date, interval_months, tags и members. Имена полей совпадают с JSON-именами Subscription и,
+// кроме tags и members, с колонками таблицы.
+type SubscriptionPatch struct {
+	Name           *string    `json:"name,omitempty" example:"Premium"`
+	Price          *int       `json:"price,omitempty" example:"150"`
+	Currency       *string    `json:"currency,omitempty" example:"RUB"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" example:"11111111-1111-1111-1111-111111111111"`
+	StartDate      *YearMonth `json:"start_date,omitempty" swaggertype:"string" example:"2025-11"`
+	EndDate        *YearMonth `json:"end_date,omitempty" swaggertype:"string" example:"2026-11"` // null makes the subscription open-ended
+	BillingPeriod  *string    `json:"billing_period,omitempty" enums:"weekly,monthly,quarterly,yearly,custom" example:"monthly"`
+	IntervalMonths *int       `json:"interval_months,omitempty" example:"6"`
+	AnchorDay      *int       `json:"anchor_day,omitempty" example:"1"`
+	Tags           []string   `json:"tags,omitempty" example:"family,music"` // replaces all tags, null removes them
+	Members        []Member   `json:"members,omitempty"`                     // replaces all members, null makes the owner pay everything
+
+	// present — поля, которые есть в документе, в том числе со значением null.
+	present map[string]bool
+}
+
+// nullablePatchFields — поля, которые можно сбросить через null.
+var nullablePatchFields = map[string]bool{
+	"end_date":        true,
+	"interval_months": true,
+	"tags":            true,
+	"members":         true,
+}
+
+func (p *SubscriptionPatch) UnmarshalJSON(data []byte) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("merge patch must be a JSON object: %w", err)
+	}
+
+	*p = SubscriptionPatch{present: make(map[string]bool, len(doc))}
+	for field, raw := range doc {
+		dst := p.target(field)
+		if dst == nil {
+			return fmt.Errorf("unknown or read-only field %q", field)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if !nullablePatchFields[field] {
+				return fmt.Errorf("field %q cannot be null", field)
+			}
+		} else if err := json.Unmarshal(raw, dst); err != nil {
+			return fmt.Errorf("field %q: %w", field, err)
+		}
+		p.present[field] = true
+	}
+	return nil
+}
+
+// target возвращает указатель на поле патча по его JSON-имени.
+func (p *SubscriptionPatch) target(field string) any {
+	switch field {
+	case "name":
+		return &p.Name
+	case "price":
+		return &p.Price
+	case "currency":
+		return &p.Currency
+	case "user_id":
+		return &p.UserID
+	case "start_date":
+		return &p.StartDate
+	case "end_date":
+		return &p.EndDate
+	case "billing_period":
+		return &p.BillingPeriod
+	case "interval_months":
+		return &p.IntervalMonths
+	case "anchor_day":
+		return &p.AnchorDay
+	case "tags":
+		return &p.Tags
+	case "members":
+		return &p.Members
+	}
+	return nil
+}
+
+// Fields возвращает имена полей, присутствующих в патче, в алфавитном порядке.
+func (p SubscriptionPatch) Fields() []string {
+	fields := make([]string, 0, len(p.present))
+	for f := range p.present {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Apply переносит присутствующие в патче поля в s.
+func (p SubscriptionPatch) Apply(s *Subscription) {
+	if p.present["name"] {
+		s.Name = *p.Name
+	}
+	if p.present["price"] {
+		s.Price = *p.Price
+	}
+	if p.present["currency"] {
+		s.Currency = *p.Currency
+	}
+	if p.present["user_id"] {
+		s.UserID = *p.UserID
+	}
+	if p.present["start_date"] {
+		s.StartDate = *p.StartDate
+	}
+	if p.present["end_date"] {
+		s.EndDate = p.EndDate
+	}
+	if p.present["billing_period"] {
+		s.BillingPeriod = *p.BillingPeriod
+	}
+	if p.present["interval_months"] {
+		s.IntervalMonths = p.IntervalMonths
+	}
+	if p.present["anchor_day"] {
+		s.AnchorDay = *p.AnchorDay
+	}
+	if p.present["tags"] {
+		s.Tags = p.Tags
+	}
+	if p.present["members"] {
+		s.Members = p.Members
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSubscriptionPatchApply(t *testing.T) {
+	end := YearMonth{Year: 2026, Month: 1}
+	interval := 6
+	owner := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	member := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	stored := func() Subscription {
+		endCopy, intervalCopy := end, interval
+		return Subscription{
+			ID:             1,
+			Name:           "Netflix",
+			Price:          300,
+			Currency:       "RUB",
+			UserID:         owner,
+			StartDate:      YearMonth{Year: 2025, Month: 1},
+			EndDate:        &endCopy,
+			BillingPeriod:  "custom",
+			IntervalMonths: &intervalCopy,
+			AnchorDay:      5,
+			Tags:           []string{"family"},
+			Members:        []Member{{UserID: member, Share: 1}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		doc    string
+		fields []string
+		want   func(s *Subscription)
+	}{
+		{
+			name:   "empty document changes nothing",
+			doc:    `{}`,
+			fields: []string{},
+			want:   func(s *Subscription) {},
+		},
+		{
+			name:   "value replaces only that field",
+			doc:    `{"price": 450, "name": "Netflix Premium"}`,
+			fields: []string{"name", "price"},
+			want: func(s *Subscription) {
+				s.Price = 450
+				s.Name = "Netflix Premium"
+			},
+		},
+		{
+			name:   "null clears end_date",
+			doc:    `{"end_date": null}`,
+			fields: []string{"end_date"},
+			want:   func(s *Subscription) { s.EndDate = nil },
+		},
+		{
+			name:   "value sets end_date",
+			doc:    `{"end_date": "2027-03"}`,
+			fields: []string{"end_date"},
+			want:   func(s *Subscription) { s.EndDate = &YearMonth{Year: 2027, Month: 3} },
+		},
+		{
+			name:   "null clears interval, tags and members",
+			doc:    `{"interval_months": null, "tags": null, "members": null}`,
+			fields: []string{"interval_months", "members", "tags"},
+			want: func(s *Subscription) {
+				s.IntervalMonths = nil
+				s.Tags = nil
+				s.Members = nil
+			},
+		},
+		{
+			name:   "empty array replaces tags",
+			doc:    `{"tags": []}`,
+			fields: []string{"tags"},
+			want:   func(s *Subscription) { s.Tags = []string{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p SubscriptionPatch
+			if err := json.Unmarshal([]byte(tt.doc), &p); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.doc, err)
+			}
+			if got := p.Fields(); !reflect.DeepEqual(got, tt.fields) {
+				t.Fatalf("Fields() = %v, want %v", got, tt.fields)
+			}
+
+			got, want := stored(), stored()
+			p.Apply(&got)
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Apply() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionPatchUnmarshalRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"not an object", `[1, 2]`, "must be a JSON object"},
+		{"unknown field", `{"colour": "red"}`, `unknown or read-only field "colour"`},
+		{"read-only field", `{"id": 5}`, `unknown or read-only field "id"`},
+		{"null on required field", `{"price": null}`, `field "price" cannot be null`},
+		{"null on start_date", `{"start_date": null}`, `field "start_date" cannot be null`},
+		{"wrong type", `{"price": "cheap"}`, `field "price"`},
+		{"malformed month", `{"end_date": "2025-13"}`, `field "end_date"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p SubscriptionPatch
+			err := json.Unmarshal([]byte(tt.doc), &p)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Unmarshal(%s) error = %v, want it to contain %q", tt.doc, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+// PricePeriod — цена подписки за один период оплаты, действующая с месяца EffectiveFrom
+// до начала следующего периода цены.
+type PricePeriod struct {
+	EffectiveFrom YearMonth `json:"effective_from" swaggertype:"string" example:"2025-11"`
+	Price         int       `json:"price" example:"150"`
+}
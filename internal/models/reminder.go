@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+// Виды напоминаний о подписке.
+const (
+	ReminderRenewal = "renewal" // предстоящее списание
+	ReminderEnding  = "ending"  // подписка заканчивается (последний день месяца end_date)
+)
+
+// Reminder — напоминание участнику подписки о событии в дату Date.
+// Amount и Currency заданы только для списаний: доля участника в валюте подписки.
+type Reminder struct {
+	Kind           string    `json:"kind" enums:"renewal,ending" example:"renewal"`
+	SubscriptionID int       `json:"subscription_id" example:"1"`
+	Name           string    `json:"name" example:"Premium"`
+	UserID         uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	Date           Date      `json:"date" swaggertype:"string" example:"2025-11-01"`
+	Amount         *int      `json:"amount,omitempty" example:"100"`
+	Currency       string    `json:"currency,omitempty" example:"RUB"`
+}
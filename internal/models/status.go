@@ -0,0 +1,41 @@
+package models
+
+// Состояния жизненного цикла подписки. Списания начисляются только в состоянии active.
+const (
+	StatusTrial     = "trial"
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+)
+
+// statusTransitions — допустимые переходы: trial → active → paused → active → cancelled,
+// отменить можно подписку в любом состоянии, кроме отменённой.
+var statusTransitions = map[string][]string{
+	StatusTrial:  {StatusActive, StatusCancelled},
+	StatusActive: {StatusPaused, StatusCancelled},
+	StatusPaused: {StatusActive, StatusCancelled},
+}
+
+func IsKnownStatus(status string) bool {
+	switch status {
+	case StatusTrial, StatusActive, StatusPaused, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransition сообщает, можно ли перевести подписку из состояния from в to.
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusPeriod — состояние подписки, действующее с месяца EffectiveFrom до начала следующего периода.
+type StatusPeriod struct {
+	Status        string    `json:"status" enums:"trial,active,paused,cancelled" example:"active"`
+	EffectiveFrom YearMonth `json:"effective_from" swaggertype:"string" example:"2025-11"`
+}
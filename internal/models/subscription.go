@@ -1,12 +1,26 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Subscription struct {
-	ID        int       `json:"id" example:"1"`
-	Name      string    `json:"name" example:"Premium"`
-	Price     int       `json:"price" example:"100"`
-	UserID    uuid.UUID `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
-	StartDate string    `json:"start_date" example:"2025-11"`
-	EndDate   string    `json:"end_date,omitempty" example:"2026-11"`
+	ID             int        `json:"id" example:"1"`
+	ServiceID      int        `json:"service_id" example:"1"` // catalog service, resolved from name; name may be omitted when service_id is set
+	Name           string     `json:"name" example:"Premium"` // canonical name of the service, aliases are accepted on input
+	Price          int        `json:"price" example:"100"`    // current price of one billing period, see /subscriptions/{id}/prices
+	Currency       string     `json:"currency" example:"RUB"`
+	UserID         uuid.UUID  `json:"user_id" example:"11111111-1111-1111-1111-111111111111"`
+	StartDate      YearMonth  `json:"start_date" swaggertype:"string" example:"2025-11"`
+	EndDate        *YearMonth `json:"end_date,omitempty" swaggertype:"string" example:"2026-11"` // omitted for an open-ended subscription
+	BillingPeriod  string     `json:"billing_period" enums:"weekly,monthly,quarterly,yearly,custom" example:"monthly"`
+	IntervalMonths *int       `json:"interval_months,omitempty" example:"6"`                         // only for custom billing_period
+	AnchorDay      int        `json:"anchor_day" example:"1"`                                        // day of month, or ISO day of week for weekly billing
+	Tags           []string   `json:"tags" example:"family,music"`                                   // lower-case labels, see the tags filter of the list; omitted on PUT keeps the stored tags
+	Members        []Member   `json:"members"`                                                       // participants sharing the cost; empty means the owner user_id pays everything; omitted on PUT keeps the stored members
+	Status         string     `json:"status" enums:"trial,active,paused,cancelled" example:"active"` // changed only by status transitions
+	Version        int        `json:"version" example:"1"`                                           // incremented on every change, sent as ETag
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`                                          // set for soft-deleted subscriptions
 }
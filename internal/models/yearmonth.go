@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	yearMonthLayout = "2006-01"
+	monthYearLayout = "01-2006"
+)
+
+// YearMonth — месяц календаря без дня. В JSON принимается в виде "YYYY-MM" или "MM-YYYY"
+// и отдаётся как "YYYY-MM", в PostgreSQL хранится в колонке DATE первым числом месяца.
+type YearMonth struct {
+	Year  int
+	Month time.Month
+}
+
+// ParseYearMonth разбирает строку формата "YYYY-MM" или "MM-YYYY".
+func ParseYearMonth(s string) (YearMonth, error) {
+	if len(s) != len(yearMonthLayout) {
+		return YearMonth{}, fmt.Errorf("invalid month %q, must be YYYY-MM or MM-YYYY", s)
+	}
+
+	layout := monthYearLayout
+	if s[4] == '-' {
+		layout = yearMonthLayout
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return YearMonth{}, fmt.Errorf("invalid month %q, must be YYYY-MM or MM-YYYY", s)
+	}
+
+	return YearMonthOf(t), nil
+}
+
+// YearMonthOf возвращает месяц, в который попадает t.
+func YearMonthOf(t time.Time) YearMonth {
+	return YearMonth{Year: t.Year(), Month: t.Month()}
+}
+
+// ChangeMonth возвращает месяц, с которого действует изменение подписки, начинающейся в start,
+// сделанное сейчас: текущий месяц или, для ещё не начавшейся подписки, её начало.
+// Это и самый поздний месяц, с которого можно задать изменение.
+func ChangeMonth(start YearMonth) YearMonth {
+	month := YearMonthOf(time.Now())
+	if month.Before(start) {
+		return start
+	}
+	return month
+}
+
+// Time возвращает полночь первого числа месяца в UTC.
+func (ym YearMonth) Time() time.Time {
+	return time.Date(ym.Year, ym.Month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (ym YearMonth) FirstDay() Date {
+	return Date{Time: ym.Time()}
+}
+
+func (ym YearMonth) LastDay() Date {
+	return Date{Time: ym.Time().AddDate(0, 1, -1)}
+}
+
+func (ym YearMonth) IsZero() bool {
+	return ym == YearMonth{}
+}
+
+func (ym YearMonth) Before(other YearMonth) bool {
+	return ym.Year < other.Year || (ym.Year == other.Year && ym.Month < other.Month)
+}
+
+func (ym YearMonth) String() string {
+	if ym.IsZero() {
+		return ""
+	}
+	return ym.Time().Format(yearMonthLayout)
+}
+
+func (ym YearMonth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ym.String())
+}
+
+func (ym *YearMonth) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("month must be a string: %w", err)
+	}
+
+	parsed, err := ParseYearMonth(s)
+	if err != nil {
+		return err
+	}
+
+	*ym = parsed
+	return nil
+}
+
+// ScanDate реализует pgtype.DateScanner для чтения колонки DATE.
+func (ym *YearMonth) ScanDate(v pgtype.Date) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into YearMonth")
+	}
+	if v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("cannot scan infinite date into YearMonth")
+	}
+
+	*ym = YearMonthOf(v.Time)
+	return nil
+}
+
+// DateValue реализует pgtype.DateValuer для записи в колонку DATE.
+func (ym YearMonth) DateValue() (pgtype.Date, error) {
+	return pgtype.Date{Time: ym.Time(), Valid: true}, nil
+}
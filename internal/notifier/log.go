@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier пишет напоминания в лог приложения. Используется, пока не подключена
+// настоящая доставка, и как образец реализации service.Notifier.
+type LogNotifier struct {
+	log logger.Logger
+}
+
+func NewLogNotifier(env string) *LogNotifier {
+	return &LogNotifier{log: logger.NewLogger(env)}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, rem models.Reminder) error {
+	fields := []zap.Field{
+		zap.String("kind", rem.Kind),
+		zap.Int("subscription_id", rem.SubscriptionID),
+		zap.String("name", rem.Name),
+		zap.String("user_id", rem.UserID.String()),
+		zap.String("date", rem.Date.String()),
+	}
+	if rem.Amount != nil {
+		fields = append(fields, zap.Int("amount", *rem.Amount), zap.String("currency", rem.Currency))
+	}
+	n.log.Info(ctx, "Subscription reminder", fields...)
+	return nil
+}
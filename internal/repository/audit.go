@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// writeAudit добавляет запись в журнал изменений в транзакции tx, в которой сделано само изменение.
+// Автор и идентификатор запроса берутся из контекста.
+func (r *Repository) writeAudit(ctx context.Context, tx pgx.Tx, action string, before, after *models.Subscription) error {
+	id := 0
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+
+	actor := logger.Actor(ctx)
+	if actor == "" {
+		actor = models.SystemActor
+	}
+
+	var requestID any
+	if rid := logger.RequestID(ctx); rid != "" {
+		requestID = rid
+	}
+
+	sql, args, err := r.query.
+		Insert("subscription_audit").
+		Columns("subscription_id", "action", "before", "after", "actor", "request_id").
+		Values(id, action, before, after, actor, requestID).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.writeAudit: builder failed", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.writeAudit: exec failed", zap.Error(err))
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	return nil
+}
+
+// SelectHistory возвращает журнал изменений подписки в хронологическом порядке.
+// История остаётся доступной и после окончательного удаления подписки.
+func (r *Repository) SelectHistory(ctx context.Context, id int) ([]models.AuditEntry, error) {
+	sql, args, err := r.query.
+		Select("id", "subscription_id", "action", "before", "after", "actor", "COALESCE(request_id, '')", "changed_at").
+		From("subscription_audit").
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("changed_at", "id").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectHistory: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectHistory: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectHistory: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.SubscriptionID, &e.Action, &e.Before, &e.After, &e.Actor, &e.RequestID, &e.ChangedAt); err != nil {
+			r.log.Error(ctx, "Repository.SelectHistory: scan failed", zap.Error(err))
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// budgetColumns — порядок колонок, который ожидает scanBudget.
+var budgetColumns = []string{"id", "user_id", "category", "service_id", "monthly_limit", "currency"}
+
+func scanBudget(row pgx.Row, b *models.Budget) error {
+	return row.Scan(&b.ID, &b.UserID, &b.Category, &b.ServiceID, &b.Limit, &b.Currency)
+}
+
+// SelectBudgets возвращает бюджеты пользователя в порядке создания.
+func (r *Repository) SelectBudgets(ctx context.Context, userID uuid.UUID) ([]models.Budget, error) {
+	return r.selectBudgets(ctx, "SelectBudgets", squirrel.Eq{"user_id": userID})
+}
+
+// SelectAllBudgets возвращает бюджеты всех пользователей; используется фоновой проверкой бюджетов.
+func (r *Repository) SelectAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	return r.selectBudgets(ctx, "SelectAllBudgets", nil)
+}
+
+func (r *Repository) selectBudgets(ctx context.Context, op string, where squirrel.Sqlizer) ([]models.Budget, error) {
+	builder := r.query.
+		Select(budgetColumns...).
+		From("budgets").
+		OrderBy("id")
+	if where != nil {
+		builder = builder.Where(where)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository."+op+": builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository."+op+": executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository."+op+": query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []models.Budget{}
+	for rows.Next() {
+		var b models.Budget
+		if err := scanBudget(rows, &b); err != nil {
+			r.log.Error(ctx, "Repository."+op+": scan failed", zap.Error(err))
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+
+	return budgets, rows.Err()
+}
+
+// InsertBudget создаёт бюджет; в budget.ID попадает его id.
+func (r *Repository) InsertBudget(ctx context.Context, budget *models.Budget) error {
+	sql, args, err := r.query.
+		Insert("budgets").
+		Columns("user_id", "category", "service_id", "monthly_limit", "currency").
+		Values(budget.UserID, budget.Category, budget.ServiceID, budget.Limit, budget.Currency).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.InsertBudget: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.InsertBudget: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&budget.ID); err != nil {
+		r.log.Error(ctx, "Repository.InsertBudget: query failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// UpdateBudget заменяет область, лимит и валюту бюджета budget.ID пользователя budget.UserID.
+func (r *Repository) UpdateBudget(ctx context.Context, budget *models.Budget) error {
+	sql, args, err := r.query.
+		Update("budgets").
+		Set("category", budget.Category).
+		Set("service_id", budget.ServiceID).
+		Set("monthly_limit", budget.Limit).
+		Set("currency", budget.Currency).
+		Where(squirrel.Eq{"id": budget.ID, "user_id": budget.UserID}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.UpdateBudget: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.UpdateBudget: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.UpdateBudget: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: budget %d", models.ErrNotFound, budget.ID)
+	}
+	return nil
+}
+
+// DeleteBudget удаляет бюджет id пользователя userID. События бюджета остаются в журнале.
+func (r *Repository) DeleteBudget(ctx context.Context, userID uuid.UUID, id int) error {
+	sql, args, err := r.query.
+		Delete("budgets").
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.DeleteBudget: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.DeleteBudget: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.DeleteBudget: exec failed", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: budget %d", models.ErrNotFound, id)
+	}
+	return nil
+}
+
+// RecordBudgetMonth сравнивает прогноз месяца с последним событием бюджета за этот месяц
+// и, если бюджет перешёл через лимит, пишет событие exceeded или recovered и возвращает его.
+// Строка бюджета блокируется, поэтому параллельные проверки не пишут одно событие дважды.
+// Если перехода нет или бюджет уже удалён, возвращается nil.
+func (r *Repository) RecordBudgetMonth(ctx context.Context, b models.Budget, m models.BudgetMonth) (*models.BudgetEvent, error) {
+	lockSQL, lockArgs, err := r.query.
+		Select("id").
+		From("budgets").
+		Where(squirrel.Eq{"id": b.ID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.RecordBudgetMonth: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	lastSQL, lastArgs, err := r.query.
+		Select("kind").
+		From("budget_events").
+		Where(squirrel.Eq{"budget_id": b.ID, "month": m.Month}).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.RecordBudgetMonth: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	var event *models.BudgetEvent
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var id int
+		if err := tx.QueryRow(ctx, lockSQL, lockArgs...).Scan(&id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			r.log.Error(ctx, "Repository.RecordBudgetMonth: lock failed", zap.Error(err))
+			return err
+		}
+
+		var last string
+		if err := tx.QueryRow(ctx, lastSQL, lastArgs...).Scan(&last); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			r.log.Error(ctx, "Repository.RecordBudgetMonth: query failed", zap.Error(err))
+			return err
+		}
+
+		// Возврат в бюджет — событие, только если до этого был выход за него
+		kind := models.BudgetRecovered
+		if m.OverBudget {
+			kind = models.BudgetExceeded
+		}
+		if kind == last || (kind == models.BudgetRecovered && last == "") {
+			return nil
+		}
+
+		sql, args, err := r.query.
+			Insert("budget_events").
+			Columns("budget_id", "user_id", "month", "kind", "projected", "monthly_limit", "currency").
+			Values(b.ID, b.UserID, m.Month, kind, m.Projected, m.Limit, b.Currency).
+			Suffix("RETURNING id, created_at").
+			ToSql()
+		if err != nil {
+			r.log.Error(ctx, "Repository.RecordBudgetMonth: builder failed", zap.Error(err))
+			return err
+		}
+
+		r.log.Debug(ctx, "Repository.RecordBudgetMonth: executing SQL",
+			zap.String("sql", sql),
+			zap.Any("args", args))
+
+		e := models.BudgetEvent{
+			BudgetID:  b.ID,
+			UserID:    b.UserID,
+			Month:     m.Month,
+			Kind:      kind,
+			Projected: m.Projected,
+			Limit:     m.Limit,
+			Currency:  b.Currency,
+		}
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&e.ID, &e.CreatedAt); err != nil {
+			r.log.Error(ctx, "Repository.RecordBudgetMonth: insert failed", zap.Error(err))
+			return err
+		}
+		event = &e
+		return nil
+	})
+	return event, err
+}
+
+// SelectBudgetEvents возвращает до limit событий бюджетов с ID больше after по возрастанию ID:
+// события пользователя *userID или, если userID равен nil, всех пользователей.
+func (r *Repository) SelectBudgetEvents(ctx context.Context, userID *uuid.UUID, after int64, limit int) ([]models.BudgetEvent, error) {
+	builder := r.query.
+		Select("id", "budget_id", "user_id", "month", "kind", "projected", "monthly_limit", "currency", "created_at").
+		From("budget_events").
+		Where(squirrel.Gt{"id": after}).
+		OrderBy("id").
+		Limit(uint64(limit))
+	if userID != nil {
+		builder = builder.Where(squirrel.Eq{"user_id": *userID})
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectBudgetEvents: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectBudgetEvents: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectBudgetEvents: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.BudgetEvent{}
+	for rows.Next() {
+		var e models.BudgetEvent
+		if err := rows.Scan(
+			&e.ID, &e.BudgetID, &e.UserID, &e.Month, &e.Kind, &e.Projected, &e.Limit, &e.Currency, &e.CreatedAt,
+		); err != nil {
+			r.log.Error(ctx, "Repository.SelectBudgetEvents: scan failed", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// serviceByName отбирает строки, у которых колонка column ссылается на сервис с названием
+// или псевдонимом name без учёта регистра.
+func serviceByName(column, name string) squirrel.Sqlizer {
+	return squirrel.Expr(column+" = (SELECT service_id FROM service_names WHERE name_key = lower(?))", name)
+}
+
+// serviceIDOf — id сервиса с названием или псевдонимом name, для записи в subscriptions.service_id.
+func serviceIDOf(name string) squirrel.Sqlizer {
+	return squirrel.Expr("(SELECT service_id FROM service_names WHERE name_key = lower(?))", name)
+}
+
+// serviceNameOf — каноническое название сервиса с названием или псевдонимом name.
+func serviceNameOf(name string) squirrel.Sqlizer {
+	return squirrel.Expr(`(
+	SELECT sv.name FROM service_names AS n JOIN services AS sv ON sv.id = n.service_id
+	WHERE n.name_key = lower(?)
+)`, name)
+}
+
+// ensureService добавляет в каталог сервис name в валюте currency, если ни один сервис ещё
+// не называется так ни названием, ни псевдонимом. Вызывается в транзакции записи подписки,
+// поэтому при откате подписки откатывается и новый сервис. Если тот же сервис параллельно
+// добавила другая транзакция, вставка откатывается до точки сохранения, и подписка ссылается
+// на уже добавленный сервис.
+func (r *Repository) ensureService(ctx context.Context, tx pgx.Tx, name, currency string) error {
+	r.log.Debug(ctx, "Repository.ensureService: executing SQL",
+		zap.String("name", name),
+		zap.String("currency", currency))
+
+	err := pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+		_, err := sp.Exec(ctx, `INSERT INTO services (name, currency)
+SELECT $1::text, $2::text
+WHERE NOT EXISTS (SELECT 1 FROM service_names WHERE name_key = lower($1::text))`, name, currency)
+		return err
+	})
+	if err != nil && !isUniqueViolation(err) {
+		r.log.Error(ctx, "Repository.ensureService: insert failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// inCategory отбирает строки, у которых колонка column ссылается на сервис категории category
+// без учёта регистра.
+func inCategory(column, category string) squirrel.Sqlizer {
+	return squirrel.Expr(column+" IN (SELECT id FROM services WHERE lower(category) = lower(?))", category)
+}
+
+// selectServices выбирает сервисы вместе с псевдонимами в порядке scanService.
+func (r *Repository) selectServices() squirrel.SelectBuilder {
+	return r.query.
+		Select(
+			"sv.id", "sv.name",
+			"COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')",
+			"sv.category", "sv.default_price", "sv.currency",
+		).
+		From("services AS sv").
+		LeftJoin("service_aliases AS a ON a.service_id = sv.id").
+		GroupBy("sv.id")
+}
+
+func scanService(row pgx.Row, s *models.Service) error {
+	return row.Scan(&s.ID, &s.Name, &s.Aliases, &s.Category, &s.DefaultPrice, &s.Currency)
+}
+
+// SelectServices возвращает каталог сервисов по алфавиту; непустой category отбирает одну категорию.
+func (r *Repository) SelectServices(ctx context.Context, category string) ([]models.Service, error) {
+	builder := r.selectServices().OrderBy("sv.name")
+	if category != "" {
+		builder = builder.Where(squirrel.Expr("lower(sv.category) = lower(?)", category))
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectServices: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectServices: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectServices: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	services := []models.Service{}
+	for rows.Next() {
+		var s models.Service
+		if err := scanService(rows, &s); err != nil {
+			r.log.Error(ctx, "Repository.SelectServices: scan failed", zap.Error(err))
+			return nil, err
+		}
+		services = append(services, s)
+	}
+
+	return services, rows.Err()
+}
+
+func (r *Repository) selectService(ctx context.Context, op string, where squirrel.Sqlizer) (models.Service, error) {
+	sql, args, err := r.selectServices().Where(where).ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository."+op+": builder failed", zap.Error(err))
+		return models.Service{}, err
+	}
+
+	r.log.Debug(ctx, "Repository."+op+": executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	var s models.Service
+	err = scanService(r.db.QueryRow(ctx, sql, args...), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Service{}, models.ErrNotFound
+	}
+	if err != nil {
+		r.log.Error(ctx, "Repository."+op+": query failed", zap.Error(err))
+	}
+	return s, err
+}
+
+func (r *Repository) SelectServiceByID(ctx context.Context, id int) (models.Service, error) {
+	s, err := r.selectService(ctx, "SelectServiceByID", squirrel.Eq{"sv.id": id})
+	if errors.Is(err, models.ErrNotFound) {
+		return s, fmt.Errorf("%w: service %d", models.ErrNotFound, id)
+	}
+	return s, err
+}
+
+// ResolveService находит сервис по названию или псевдониму без учёта регистра.
+func (r *Repository) ResolveService(ctx context.Context, name string) (models.Service, error) {
+	s, err := r.selectService(ctx, "ResolveService", serviceByName("sv.id", name))
+	if errors.Is(err, models.ErrNotFound) {
+		return s, fmt.Errorf("%w: service %q", models.ErrNotFound, name)
+	}
+	return s, err
+}
+
+// insertAliases записывает псевдонимы сервиса id.
+func (r *Repository) insertAliases(ctx context.Context, tx pgx.Tx, id int, aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	builder := r.query.
+		Insert("service_aliases").
+		Columns("service_id", "alias")
+	for _, alias := range aliases {
+		builder = builder.Values(id, alias)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.insertAliases: builder failed", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.insertAliases: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// InsertService добавляет сервис в каталог вместе с псевдонимами; в service.ID попадает его id.
+func (r *Repository) InsertService(ctx context.Context, service *models.Service) error {
+	sql, args, err := r.query.
+		Insert("services").
+		Columns("name", "category", "default_price", "currency").
+		Values(service.Name, service.Category, service.DefaultPrice, service.Currency).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.InsertService: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.InsertService: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&service.ID); err != nil {
+			r.log.Error(ctx, "Repository.InsertService: query failed", zap.Error(err))
+			return mapError(err)
+		}
+		return r.insertAliases(ctx, tx, service.ID, service.Aliases)
+	})
+}
+
+// UpdateService заменяет поля и псевдонимы сервиса. Новое название сразу записывается
+// в подписки сервиса, каждая из них получает новую версию и запись аудита. Название или
+// псевдоним, занятые другим сервисом, отклоняются базой (service_names): models.ErrConflict.
+func (r *Repository) UpdateService(ctx context.Context, service *models.Service) error {
+	sql, args, err := r.query.
+		Update("services").
+		Set("name", service.Name).
+		Set("category", service.Category).
+		Set("default_price", service.DefaultPrice).
+		Set("currency", service.Currency).
+		Where(squirrel.Eq{"id": service.ID}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.UpdateService: builder failed", zap.Error(err))
+		return err
+	}
+
+	deleteSQL, deleteArgs, err := r.query.
+		Delete("service_aliases").
+		Where(squirrel.Eq{"service_id": service.ID}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.UpdateService: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.UpdateService: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Псевдонимы удаляются до смены названия: новое название может быть прежним псевдонимом
+		if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+			r.log.Error(ctx, "Repository.UpdateService: delete aliases failed", zap.Error(err))
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			r.log.Error(ctx, "Repository.UpdateService: exec failed", zap.Error(err))
+			return mapError(err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: service %d", models.ErrNotFound, service.ID)
+		}
+		if err := r.insertAliases(ctx, tx, service.ID, service.Aliases); err != nil {
+			return err
+		}
+		return r.renameSubscriptions(ctx, tx, service.ID, service.Name)
+	})
+}
+
+// renameSubscriptions записывает название name в подписки сервиса id, у которых оно другое.
+func (r *Repository) renameSubscriptions(ctx context.Context, tx pgx.Tx, id int, name string) error {
+	where := squirrel.And{squirrel.Eq{"service_id": id}, squirrel.NotEq{"name": name}}
+
+	lockSQL, lockArgs, err := r.query.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where(where).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.renameSubscriptions: builder failed", zap.Error(err))
+		return err
+	}
+
+	sql, args, err := r.query.
+		Update("subscriptions").
+		Set("name", name).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(where).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.renameSubscriptions: builder failed", zap.Error(err))
+		return err
+	}
+
+	before, err := r.collectSubscriptions(ctx, tx, lockSQL, lockArgs)
+	if err != nil {
+		return err
+	}
+	after, err := r.collectSubscriptions(ctx, tx, sql, args)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]models.Subscription, len(before))
+	for _, s := range before {
+		byID[s.ID] = s
+	}
+	for i := range after {
+		old := byID[after[i].ID]
+		if err := r.writeAudit(ctx, tx, models.AuditUpdate, &old, &after[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectSubscriptions выполняет в tx запрос, возвращающий строки подписок, и читает их целиком.
+func (r *Repository) collectSubscriptions(ctx context.Context, tx pgx.Tx, sql string, args []any) ([]models.Subscription, error) {
+	r.log.Debug(ctx, "Repository.collectSubscriptions: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.collectSubscriptions: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.Subscription
+	for rows.Next() {
+		var s models.Subscription
+		if err := scanSubscription(rows, &s); err != nil {
+			r.log.Error(ctx, "Repository.collectSubscriptions: scan failed", zap.Error(err))
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteService удаляет сервис из каталога. Сервис, на который ссылаются подписки
+// (в том числе мягко удалённые), удалить нельзя: возвращается models.ErrConflict.
+func (r *Repository) DeleteService(ctx context.Context, id int) error {
+	sql, args, err := r.query.
+		Delete("services").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.DeleteService: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.DeleteService: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.DeleteService: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: service %d", models.ErrNotFound, id)
+	}
+	return nil
+}
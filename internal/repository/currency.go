@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ratesBatchSize ограничивает число строк в одном INSERT, чтобы не упереться в лимит параметров.
+const ratesBatchSize = 1000
+
+// UpsertRates сохраняет курсы валют в одной транзакции, заменяя уже загруженные на те же даты.
+func (r *Repository) UpsertRates(ctx context.Context, rates []models.CurrencyRate) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		for start := 0; start < len(rates); start += ratesBatchSize {
+			batch := rates[start:min(start+ratesBatchSize, len(rates))]
+
+			builder := r.query.
+				Insert("currency_rates").
+				Columns("currency", "rate_date", "rate").
+				Suffix("ON CONFLICT (currency, rate_date) DO UPDATE SET rate = EXCLUDED.rate")
+			for _, rate := range batch {
+				builder = builder.Values(rate.Currency, rate.Date, rate.Rate)
+			}
+
+			sql, args, err := builder.ToSql()
+			if err != nil {
+				r.log.Error(ctx, "Repository.UpsertRates: builder failed", zap.Error(err))
+				return err
+			}
+
+			r.log.Debug(ctx, "Repository.UpsertRates: executing SQL",
+				zap.String("sql", sql),
+				zap.Int("rates_count", len(batch)))
+
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				r.log.Error(ctx, "Repository.UpsertRates: exec failed", zap.Error(err))
+				return mapError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// SelectRates возвращает загруженные курсы, пустая currency означает все валюты.
+func (r *Repository) SelectRates(ctx context.Context, currency string) ([]models.CurrencyRate, error) {
+	builder := r.query.
+		Select("currency", "rate_date", "rate").
+		From("currency_rates").
+		OrderBy("currency", "rate_date")
+
+	if currency != "" {
+		builder = builder.Where(squirrel.Eq{"currency": currency})
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectRates: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectRates: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectRates: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.CurrencyRate
+	for rows.Next() {
+		var rate models.CurrencyRate
+		if err := rows.Scan(&rate.Currency, &rate.Date, &rate.Rate); err != nil {
+			r.log.Error(ctx, "Repository.SelectRates: scan failed", zap.Error(err))
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"effective_mobile/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAfterCursor(t *testing.T) {
+	march := models.YearMonth{Year: 2025, Month: 3}
+
+	tests := []struct {
+		name     string
+		sort     string
+		cursor   models.PageCursor
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "id only",
+			cursor:   models.PageCursor{ID: 7},
+			wantSQL:  "((id > ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "ascending field breaks ties by id",
+			sort:     "name",
+			cursor:   models.PageCursor{Sort: "name", Values: map[string]string{"name": "Netflix"}, ID: 7},
+			wantSQL:  "((name > ?) OR (name = ? AND id > ?))",
+			wantArgs: []any{"Netflix", "Netflix", 7},
+		},
+		{
+			name:     "descending field",
+			sort:     "-price",
+			cursor:   models.PageCursor{Sort: "-price", Values: map[string]string{"price": "300"}, ID: 7},
+			wantSQL:  "((price < ?) OR (price = ? AND id > ?))",
+			wantArgs: []any{300, 300, 7},
+		},
+		{
+			name:     "nullable field with value: nulls come last",
+			sort:     "-end_date",
+			cursor:   models.PageCursor{Sort: "-end_date", Values: map[string]string{"end_date": "2025-03"}, ID: 7},
+			wantSQL:  "(((end_date < ? OR end_date IS NULL)) OR (end_date = ? AND id > ?))",
+			wantArgs: []any{march, march, 7},
+		},
+		{
+			name:     "nullable field at null: only nulls follow",
+			sort:     "end_date",
+			cursor:   models.PageCursor{Sort: "end_date", Values: map[string]string{}, ID: 7},
+			wantSQL:  "((end_date IS NULL AND id > ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "two fields",
+			sort:     "-price,name",
+			cursor:   models.PageCursor{Sort: "-price,name", Values: map[string]string{"price": "300", "name": "A"}, ID: 7},
+			wantSQL:  "((price < ?) OR (price = ? AND name > ?) OR (price = ? AND name = ? AND id > ?))",
+			wantArgs: []any{300, 300, "A", 300, "A", 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := models.ParseSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.sort, err)
+			}
+
+			cond, err := afterCursor(sort, tt.cursor)
+			if err != nil {
+				t.Fatalf("afterCursor: %v", err)
+			}
+			sql, args, err := cond.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q\nwant  %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestAfterCursorRejectsMalformedValues(t *testing.T) {
+	tests := []struct {
+		sort   string
+		values map[string]string
+	}{
+		{"price", map[string]string{"price": "abc"}},
+		{"user_id", map[string]string{"user_id": "not-a-uuid"}},
+		{"start_date", map[string]string{"start_date": "2025-13"}},
+		// NULL допустим только в nullable колонках
+		{"name", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		sort, _ := models.ParseSort(tt.sort)
+		c := models.PageCursor{Sort: tt.sort, Values: tt.values, ID: 1}
+		if _, err := afterCursor(sort, c); !errors.Is(err, models.ErrInvalidCursor) {
+			t.Errorf("afterCursor(%s, %v) error = %v, want ErrInvalidCursor", tt.sort, tt.values, err)
+		}
+	}
+}
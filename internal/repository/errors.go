@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"effective_mobile/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды ошибок PostgreSQL, которые переводятся в ошибки предметной области.
+const (
+	pgNotNullViolation     = "23502"
+	pgForeignKeyViolation  = "23503"
+	pgUniqueViolation      = "23505"
+	pgCheckViolation       = "23514"
+	pgExclusionViolation   = "23P01"
+	pgInvalidTextRepr      = "22P02"
+	pgNumericOutOfRange    = "22003"
+	pgStringDataRightTrunc = "22001"
+)
+
+// mapError переводит ошибку PostgreSQL в models.ErrConflict или models.ErrValidation.
+// В текст попадает только имя нарушенного ограничения или колонки, сообщение базы остаётся в логах.
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation, pgExclusionViolation:
+		return fmt.Errorf("%w: constraint %s violated", models.ErrConflict, pgErr.ConstraintName)
+	case pgForeignKeyViolation:
+		return fmt.Errorf("%w: referenced record constraint %s violated", models.ErrConflict, pgErr.ConstraintName)
+	case pgCheckViolation:
+		return fmt.Errorf("%w: constraint %s violated", models.ErrValidation, pgErr.ConstraintName)
+	case pgNotNullViolation:
+		return fmt.Errorf("%w: column %s must not be null", models.ErrValidation, pgErr.ColumnName)
+	case pgInvalidTextRepr, pgNumericOutOfRange, pgStringDataRightTrunc:
+		return fmt.Errorf("%w: value out of range or malformed", models.ErrValidation)
+	default:
+		return err
+	}
+}
+
+// isUniqueViolation сообщает, нарушено ли ограничение уникальности.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
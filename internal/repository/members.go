@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// membersColumn — участники подписки в виде JSON-массива models.Member по user_id;
+// выражение ссылается на таблицу subscriptions без псевдонима.
+const membersColumn = `COALESCE((
+	SELECT json_agg(json_build_object('user_id', m.user_id, 'share', m.share) ORDER BY m.user_id)
+	FROM subscription_members AS m WHERE m.subscription_id = subscriptions.id
+), '[]'::json) AS members`
+
+// replaceMembers заменяет участников подписки id на members.
+func (r *Repository) replaceMembers(ctx context.Context, tx pgx.Tx, id int, members []models.Member) error {
+	deleteSQL, deleteArgs, err := r.query.
+		Delete("subscription_members").
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.replaceMembers: builder failed", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+		r.log.Error(ctx, "Repository.replaceMembers: delete failed", zap.Error(err))
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	builder := r.query.
+		Insert("subscription_members").
+		Columns("subscription_id", "user_id", "share")
+	for _, m := range members {
+		builder = builder.Values(id, m.UserID, m.Share)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.replaceMembers: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.replaceMembers: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.replaceMembers: insert failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
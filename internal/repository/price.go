@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// upsertPrice записывает период цены, заменяя цену периода с тем же началом.
+func (r *Repository) upsertPrice(ctx context.Context, tx pgx.Tx, id int, p models.PricePeriod) error {
+	sql, args, err := r.query.
+		Insert("subscription_prices").
+		Columns("subscription_id", "effective_from", "price").
+		Values(id, p.EffectiveFrom, p.Price).
+		Suffix("ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.upsertPrice: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.upsertPrice: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.upsertPrice: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// SelectPrices возвращает периоды цены подписки по возрастанию даты начала.
+func (r *Repository) SelectPrices(ctx context.Context, id int) ([]models.PricePeriod, error) {
+	sql, args, err := r.query.
+		Select("effective_from", "price").
+		From("subscription_prices").
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("effective_from").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectPrices: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectPrices: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectPrices: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []models.PricePeriod{}
+	for rows.Next() {
+		var p models.PricePeriod
+		if err := rows.Scan(&p.EffectiveFrom, &p.Price); err != nil {
+			r.log.Error(ctx, "Repository.SelectPrices: scan failed", zap.Error(err))
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+// AddPrice записывает период цены подписки id и приводит её текущую цену к последнему периоду.
+// Если version больше нуля, подписка изменяется только в этой версии.
+func (r *Repository) AddPrice(ctx context.Context, id, version int, p models.PricePeriod) (models.Subscription, error) {
+	update := r.query.
+		Update("subscriptions").
+		Set("price", squirrel.Expr(`(
+	SELECT price FROM subscription_prices
+	WHERE subscription_id = subscriptions.id
+	ORDER BY effective_from DESC LIMIT 1
+)`))
+
+	insertPrice := func(tx pgx.Tx, _ models.Subscription) error {
+		return r.upsertPrice(ctx, tx, id, p)
+	}
+
+	return r.change(ctx, "AddPrice", models.AuditUpdate, id, version, false, update, insertPrice)
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// notReminded отбирает строки, по которым участнику member ещё не отправлено напоминание kind
+// с датой из колонки dueColumn.
+func notReminded(kind, dueColumn string) squirrel.Sqlizer {
+	return squirrel.Expr(`NOT EXISTS (
+	SELECT 1 FROM subscription_reminders AS sr
+	WHERE sr.subscription_id = s.id AND sr.user_id = member.user_id
+		AND sr.kind = ? AND sr.due_date = `+dueColumn+`
+)`, kind)
+}
+
+// SelectReminders возвращает ещё не отправленные напоминания о событиях в [from, to]:
+// списаниях по тем же правилам, что и SelectCharges (доля участника в валюте подписки),
+// и окончании подписок, кроме отменённых. Напоминания получают все участники подписки.
+func (r *Repository) SelectReminders(ctx context.Context, from, to models.Date) ([]models.Reminder, error) {
+	renewals, err := r.selectRenewals(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	endings, err := r.selectEndings(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(renewals, endings...), nil
+}
+
+func (r *Repository) selectRenewals(ctx context.Context, from, to models.Date) ([]models.Reminder, error) {
+	sql, args, err := r.chargesQuery([]string{
+		"s.id", "s.name", "member.user_id", "billed.charge_date",
+		"ROUND(charge.amount)::bigint", "s.currency",
+	}, models.ChargeQuery{From: from, To: to}).
+		Where(notReminded(models.ReminderRenewal, "billed.charge_date")).
+		OrderBy("billed.charge_date", "s.id").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.selectRenewals: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.selectRenewals: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.selectRenewals: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []models.Reminder
+	for rows.Next() {
+		rem := models.Reminder{Kind: models.ReminderRenewal}
+		if err := rows.Scan(&rem.SubscriptionID, &rem.Name, &rem.UserID, &rem.Date, &rem.Amount, &rem.Currency); err != nil {
+			r.log.Error(ctx, "Repository.selectRenewals: scan failed", zap.Error(err))
+			return nil, err
+		}
+		reminders = append(reminders, rem)
+	}
+
+	return reminders, rows.Err()
+}
+
+func (r *Repository) selectEndings(ctx context.Context, from, to models.Date) ([]models.Reminder, error) {
+	sql, args, err := r.query.
+		Select("s.id", "s.name", "member.user_id", "ending.day").
+		From("subscriptions AS s").
+		JoinClause(memberJoin).
+		// Подписка действует до последнего дня месяца end_date
+		JoinClause("CROSS JOIN LATERAL (SELECT (s.end_date + interval '1 month' - interval '1 day')::date AS day) AS ending").
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		Where(squirrel.NotEq{"s.status": models.StatusCancelled}).
+		Where(squirrel.Expr("ending.day BETWEEN ? AND ?", from, to)).
+		Where(notReminded(models.ReminderEnding, "ending.day")).
+		OrderBy("ending.day", "s.id").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.selectEndings: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.selectEndings: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.selectEndings: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []models.Reminder
+	for rows.Next() {
+		rem := models.Reminder{Kind: models.ReminderEnding}
+		if err := rows.Scan(&rem.SubscriptionID, &rem.Name, &rem.UserID, &rem.Date); err != nil {
+			r.log.Error(ctx, "Repository.selectEndings: scan failed", zap.Error(err))
+			return nil, err
+		}
+		reminders = append(reminders, rem)
+	}
+
+	return reminders, rows.Err()
+}
+
+// MarkReminderSent отмечает напоминание отправленным; повторная отметка ничего не меняет.
+func (r *Repository) MarkReminderSent(ctx context.Context, rem models.Reminder) error {
+	sql, args, err := r.query.
+		Insert("subscription_reminders").
+		Columns("subscription_id", "user_id", "kind", "due_date").
+		Values(rem.SubscriptionID, rem.UserID, rem.Kind, rem.Date).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.MarkReminderSent: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.MarkReminderSent: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.MarkReminderSent: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// SelectReminderRun возвращает день последнего успешного запуска напоминаний;
+// нулевая дата — успешных запусков ещё не было.
+func (r *Repository) SelectReminderRun(ctx context.Context) (models.Date, error) {
+	sql, args, err := r.query.
+		Select("run_date").
+		From("reminder_runs").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectReminderRun: builder failed", zap.Error(err))
+		return models.Date{}, err
+	}
+
+	var day models.Date
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&day)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Date{}, nil
+	}
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectReminderRun: query failed", zap.Error(err))
+		return models.Date{}, err
+	}
+	return day, nil
+}
+
+// SaveReminderRun запоминает день успешного запуска напоминаний.
+func (r *Repository) SaveReminderRun(ctx context.Context, day models.Date) error {
+	sql, args, err := r.query.
+		Insert("reminder_runs").
+		Columns("run_date").
+		Values(day).
+		Suffix("ON CONFLICT (id) DO UPDATE SET run_date = EXCLUDED.run_date, finished_at = now()").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SaveReminderRun: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.SaveReminderRun: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.SaveReminderRun: exec failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// upsertStatus записывает период состояния, заменяя состояние периода с тем же началом.
+func (r *Repository) upsertStatus(ctx context.Context, tx pgx.Tx, id int, p models.StatusPeriod) error {
+	sql, args, err := r.query.
+		Insert("subscription_status_periods").
+		Columns("subscription_id", "effective_from", "status").
+		Values(id, p.EffectiveFrom, p.Status).
+		Suffix("ON CONFLICT (subscription_id, effective_from) DO UPDATE SET status = EXCLUDED.status").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.upsertStatus: builder failed", zap.Error(err))
+		return err
+	}
+
+	r.log.Debug(ctx, "Repository.upsertStatus: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		r.log.Error(ctx, "Repository.upsertStatus: exec failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
+
+// SelectStatuses возвращает периоды состояния подписки по возрастанию даты начала.
+func (r *Repository) SelectStatuses(ctx context.Context, id int) ([]models.StatusPeriod, error) {
+	sql, args, err := r.query.
+		Select("effective_from", "status").
+		From("subscription_status_periods").
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("effective_from").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectStatuses: builder failed", zap.Error(err))
+		return nil, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectStatuses: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectStatuses: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []models.StatusPeriod{}
+	for rows.Next() {
+		var p models.StatusPeriod
+		if err := rows.Scan(&p.EffectiveFrom, &p.Status); err != nil {
+			r.log.Error(ctx, "Repository.SelectStatuses: scan failed", zap.Error(err))
+			return nil, err
+		}
+		statuses = append(statuses, p)
+	}
+
+	return statuses, rows.Err()
+}
+
+// Transition переводит подписку id в состояние p.Status начиная с месяца p.EffectiveFrom.
+// Допустимость перехода проверяет сервис. Если version больше нуля, подписка изменяется
+// только в этой версии.
+func (r *Repository) Transition(ctx context.Context, id, version int, p models.StatusPeriod) (models.Subscription, error) {
+	update := r.query.
+		Update("subscriptions").
+		Set("status", p.Status)
+
+	recordStatus := func(tx pgx.Tx, _ models.Subscription) error {
+		return r.upsertStatus(ctx, tx, id, p)
+	}
+
+	return r.change(ctx, "Transition", models.AuditUpdate, id, version, false, update, recordStatus)
+}
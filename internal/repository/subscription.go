@@ -5,6 +5,11 @@ import (
 	"effective_mobile/internal/models"
 	"effective_mobile/pkg/logger"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -19,6 +24,20 @@ type Repository struct {
 	log   logger.Logger
 }
 
+// subscriptionColumns — порядок колонок, который ожидает scanSubscription.
+var subscriptionColumns = []string{
+	"id", "service_id", "name", "price", "currency", "user_id", "start_date", "end_date",
+	"billing_period", "interval_months", "anchor_day", "status", "version", "deleted_at", tagsColumn,
+	membersColumn,
+}
+
+func scanSubscription(row pgx.Row, s *models.Subscription) error {
+	return row.Scan(
+		&s.ID, &s.ServiceID, &s.Name, &s.Price, &s.Currency, &s.UserID, &s.StartDate, &s.EndDate,
+		&s.BillingPeriod, &s.IntervalMonths, &s.AnchorDay, &s.Status, &s.Version, &s.DeletedAt, &s.Tags, &s.Members,
+	)
+}
+
 func NewRepository(db *pgxpool.Pool, env string) *Repository {
 	return &Repository{
 		db:    db,
@@ -27,69 +46,292 @@ func NewRepository(db *pgxpool.Pool, env string) *Repository {
 	}
 }
 
-func (r *Repository) Select(ctx context.Context, limit, offset int) []models.Subscription {
-	sql, args, err := r.query.
-		Select("id", "name", "price", "user_id", "start_date", "end_date").
+// likeEscaper экранирует спецсимволы шаблона LIKE в пользовательском вводе.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// notDeleted отбирает подписки, которые не были мягко удалены.
+var notDeleted = squirrel.Eq{"deleted_at": nil}
+
+// applySubscriptionFilter добавляет к запросу условия отбора из фильтра.
+func applySubscriptionFilter(builder squirrel.SelectBuilder, f models.SubscriptionFilter) squirrel.SelectBuilder {
+	if !f.IncludeDeleted {
+		builder = builder.Where(notDeleted)
+	}
+	if f.UserID != uuid.Nil {
+		builder = builder.Where(squirrel.Eq{"user_id": f.UserID})
+	}
+	if f.Name != "" {
+		builder = builder.Where(serviceByName("service_id", f.Name))
+	}
+	if f.Status != "" {
+		builder = builder.Where(squirrel.Eq{"status": f.Status})
+	}
+	if f.Category != "" {
+		builder = builder.Where(inCategory("service_id", f.Category))
+	}
+	if len(f.Tags) > 0 {
+		builder = builder.Where(hasAllTags("id", f.Tags))
+	}
+	if f.NamePrefix != "" {
+		builder = builder.Where(squirrel.ILike{"name": likeEscaper.Replace(f.NamePrefix) + "%"})
+	}
+	if f.PriceMin != nil {
+		builder = builder.Where(squirrel.GtOrEq{"price": *f.PriceMin})
+	}
+	if f.PriceMax != nil {
+		builder = builder.Where(squirrel.LtOrEq{"price": *f.PriceMax})
+	}
+	if f.ActiveOn != nil {
+		builder = builder.
+			Where(squirrel.LtOrEq{"start_date": *f.ActiveOn}).
+			Where(squirrel.Or{squirrel.Eq{"end_date": nil}, squirrel.GtOrEq{"end_date": *f.ActiveOn}})
+	}
+	if f.StartFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"start_date": *f.StartFrom})
+	}
+	if f.StartTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"start_date": *f.StartTo})
+	}
+	if f.EndFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"end_date": *f.EndFrom})
+	}
+	if f.EndTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"end_date": *f.EndTo})
+	}
+	return builder
+}
+
+// orderBy превращает сортировку фильтра в ORDER BY. Поля проверены models.ParseSort,
+// id добавляется последним, чтобы порядок был однозначным.
+func orderBy(sort []models.SortField) []string {
+	clauses := make([]string, 0, len(sort)+1)
+	for _, f := range sort {
+		if !models.SortableFields[f.Field] {
+			continue
+		}
+		if f.Desc {
+			clauses = append(clauses, f.Field+" DESC NULLS LAST")
+		} else {
+			clauses = append(clauses, f.Field+" ASC NULLS LAST")
+		}
+	}
+	return append(clauses, "id")
+}
+
+// nullableSortFields — поля сортировки, которые могут быть NULL. Только для них условие
+// keyset-пагинации учитывает NULLS LAST; остальные колонки, включая id, объявлены NOT NULL.
+var nullableSortFields = map[string]bool{
+	"end_date": true,
+}
+
+// cursorArg приводит строковое значение из курсора к типу колонки.
+func cursorArg(field, value string) (any, error) {
+	switch field {
+	case "id", "price":
+		return strconv.Atoi(value)
+	case "user_id":
+		return uuid.Parse(value)
+	case "start_date", "end_date":
+		return models.ParseYearMonth(value)
+	default:
+		return value, nil
+	}
+}
+
+// afterCursor строит условие keyset-пагинации для сортировки из orderBy (NULLS LAST):
+// строка идёт после курсора, если совпадает с ним по первым i полям и больше (меньше для DESC)
+// по (i+1)-му. Для колонок из nullableSortFields «больше» включает и NULL.
+func afterCursor(sort []models.SortField, c models.PageCursor) (squirrel.Sqlizer, error) {
+	fields := append(append([]models.SortField{}, sort...), models.SortField{Field: "id"})
+
+	var after squirrel.Or
+	var equal squirrel.And
+	for _, f := range fields {
+		raw, ok := c.Values[f.Field]
+		if f.Field == "id" {
+			raw, ok = strconv.Itoa(c.ID), true
+		}
+
+		if !ok {
+			if !nullableSortFields[f.Field] {
+				return nil, models.ErrInvalidCursor
+			}
+			// После NULL при NULLS LAST идут только такие же NULL
+			equal = append(equal, squirrel.Eq{f.Field: nil})
+			continue
+		}
+
+		v, err := cursorArg(f.Field, raw)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+
+		var beyond squirrel.Sqlizer = squirrel.Gt{f.Field: v}
+		if f.Desc {
+			beyond = squirrel.Lt{f.Field: v}
+		}
+		if nullableSortFields[f.Field] {
+			beyond = squirrel.Or{beyond, squirrel.Eq{f.Field: nil}}
+		}
+		cond := append(squirrel.And{}, equal...)
+		cond = append(cond, beyond)
+		after = append(after, cond)
+
+		equal = append(equal, squirrel.Eq{f.Field: v})
+	}
+
+	return after, nil
+}
+
+func (r *Repository) Select(ctx context.Context, f models.SubscriptionFilter) ([]models.Subscription, error) {
+	builder := r.query.
+		Select(subscriptionColumns...).
 		From("subscriptions").
-		OrderBy("id").
-		Limit(uint64(limit)).
-		Offset(uint64(offset)).
-		ToSql()
+		OrderBy(orderBy(f.Sort)...).
+		Limit(uint64(f.Limit)).
+		Offset(uint64(f.Offset))
+	builder = applySubscriptionFilter(builder, f)
+
+	if f.After != nil {
+		cond, err := afterCursor(f.Sort, *f.After)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.Where(cond)
+	}
+
+	sql, args, err := builder.ToSql()
 	if err != nil {
 		r.log.Error(ctx, "Repository.Select: build query failed:", zap.Error(err))
-		return []models.Subscription{}
+		return nil, err
 	}
 
+	r.log.Debug(ctx, "Repository.Select: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
 	rows, err := r.db.Query(ctx, sql, args...)
 	if err != nil {
 		r.log.Error(ctx, "Repository.Select: query failed:", zap.Error(err))
-		return []models.Subscription{}
+		return nil, err
 	}
 	defer rows.Close()
 
-	var subs []models.Subscription
+	subs := []models.Subscription{}
 	for rows.Next() {
 		var s models.Subscription
-		if err := rows.Scan(&s.ID, &s.Name, &s.Price, &s.UserID, &s.StartDate, &s.EndDate); err != nil {
+		if err := scanSubscription(rows, &s); err != nil {
 			r.log.Error(ctx, "Repository.Select: scan failed:", zap.Error(err))
-			continue
+			return nil, err
 		}
 		subs = append(subs, s)
 	}
 
-	return subs
+	return subs, rows.Err()
+}
+
+// Count возвращает число подписок, подходящих под условия фильтра, без учёта пагинации.
+func (r *Repository) Count(ctx context.Context, f models.SubscriptionFilter) (int, error) {
+	builder := applySubscriptionFilter(r.query.Select("COUNT(*)").From("subscriptions"), f)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.Count: builder failed", zap.Error(err))
+		return 0, err
+	}
+
+	r.log.Debug(ctx, "Repository.Count: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	var count int
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&count)
+	return count, err
 }
 
-func (r *Repository) SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) (models.Subscription, error) {
+func (r *Repository) SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) ([]models.Subscription, error) {
 	sql, args, err := r.query.
-		Select("id", "name", "price", "user_id", "start_date", "end_date").
+		Select(subscriptionColumns...).
 		From("subscriptions").
-		Where(squirrel.Eq{"name": name, "user_id": id}).
+		Where(squirrel.Eq{"user_id": id}).
+		Where(serviceByName("service_id", name)).
+		Where(notDeleted).
+		OrderBy("id").
 		ToSql()
 	if err != nil {
 		r.log.Error(ctx, "Repository.SelectByNameAndUserID: builder failed", zap.Error(err))
-		return models.Subscription{}, err
+		return nil, err
 	}
 
 	r.log.Debug(ctx, "Repository.SelectByNameAndUserID: executing SQL",
 		zap.String("sql", sql),
 		zap.Any("args", args))
 
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectByNameAndUserID: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.Subscription
+	for rows.Next() {
+		var s models.Subscription
+		if err := scanSubscription(rows, &s); err != nil {
+			r.log.Error(ctx, "Repository.SelectByNameAndUserID: scan failed", zap.Error(err))
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *Repository) SelectByID(ctx context.Context, id int, includeDeleted bool) (models.Subscription, error) {
+	builder := r.query.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id})
+	if !includeDeleted {
+		builder = builder.Where(notDeleted)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectByID: builder failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	r.log.Debug(ctx, "Repository.SelectByID: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
 	var s models.Subscription
-	err = r.db.QueryRow(ctx, sql, args...).Scan(&s.ID, &s.Name, &s.Price, &s.UserID, &s.StartDate, &s.EndDate)
+	err = scanSubscription(r.db.QueryRow(ctx, sql, args...), &s)
 	if errors.Is(err, pgx.ErrNoRows) {
-		r.log.Info(ctx, "Repository.SelectByNameAndUserID: query failed", zap.String("name", name), zap.String("user_id", id.String()))
-		return models.Subscription{}, nil
+		r.log.Info(ctx, "Repository.SelectByID: subscription not found", zap.Int("id", id))
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d", models.ErrNotFound, id)
 	}
 	return s, err
 }
 
+// returningSubscription возвращает изменённую строку в порядке scanSubscription.
+var returningSubscription = "RETURNING " + strings.Join(subscriptionColumns, ", ")
+
+// Insert создаёт подписку и запись аудита в одной транзакции. Подписка связывается с сервисом
+// каталога по названию или псевдониму и получает его каноническое название; сервис с новым
+// названием добавляется в каталог в той же транзакции. В subscription попадает строка в том
+// виде, в каком она сохранена, вместе с id и версией.
 func (r *Repository) Insert(ctx context.Context, subscription *models.Subscription) error {
 	sql, args, err := r.query.
 		Insert("subscriptions").
-		Columns("name", "price", "user_id", "start_date", "end_date").
-		Values(subscription.Name, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate).
-		Suffix("RETURNING id").
+		Columns("service_id", "name", "price", "currency", "user_id", "start_date", "end_date", "billing_period", "interval_months", "anchor_day", "status").
+		Values(
+			serviceIDOf(subscription.Name), serviceNameOf(subscription.Name), subscription.Price, subscription.Currency, subscription.UserID,
+			subscription.StartDate, subscription.EndDate, subscription.BillingPeriod, subscription.IntervalMonths,
+			subscription.AnchorDay, subscription.Status,
+		).
+		Suffix(returningSubscription).
 		ToSql()
 	if err != nil {
 		r.log.Error(ctx, "Repository.Insert: builder failed", zap.Error(err))
@@ -101,65 +343,393 @@ func (r *Repository) Insert(ctx context.Context, subscription *models.Subscripti
 		zap.Any("args", args),
 	)
 
-	return r.db.QueryRow(ctx, sql, args...).Scan(&subscription.ID)
+	tags, members := subscription.Tags, subscription.Members
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := r.ensureService(ctx, tx, subscription.Name, subscription.Currency); err != nil {
+			return err
+		}
+		if err := scanSubscription(tx.QueryRow(ctx, sql, args...), subscription); err != nil {
+			r.log.Error(ctx, "Repository.Insert: query failed", zap.Error(err))
+			return mapError(err)
+		}
+
+		if err := r.replaceTags(ctx, tx, subscription.ID, tags); err != nil {
+			return err
+		}
+		subscription.Tags = append([]string{}, tags...)
+		sort.Strings(subscription.Tags)
+
+		if err := r.replaceMembers(ctx, tx, subscription.ID, members); err != nil {
+			return err
+		}
+		subscription.Members = append([]models.Member{}, members...)
+
+		initial := models.PricePeriod{EffectiveFrom: subscription.StartDate, Price: subscription.Price}
+		if err := r.upsertPrice(ctx, tx, subscription.ID, initial); err != nil {
+			return err
+		}
+		status := models.StatusPeriod{Status: subscription.Status, EffectiveFrom: subscription.StartDate}
+		if err := r.upsertStatus(ctx, tx, subscription.ID, status); err != nil {
+			return err
+		}
+		return r.writeAudit(ctx, tx, models.AuditCreate, nil, subscription)
+	})
 }
 
-func (r *Repository) Update(ctx context.Context, subscription models.Subscription) error {
+// lockForChange читает подписку id и блокирует её до конца транзакции. Мягко удалённая
+// подписка доступна только операциям с deleted, остальным она не видна. Если version
+// больше нуля, подписка должна быть в этой версии.
+func (r *Repository) lockForChange(ctx context.Context, tx pgx.Tx, id, version int, deleted bool) (models.Subscription, error) {
 	sql, args, err := r.query.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.lockForChange: builder failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	var s models.Subscription
+	err = scanSubscription(tx.QueryRow(ctx, sql, args...), &s)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), err == nil && s.DeletedAt != nil && !deleted:
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d", models.ErrNotFound, id)
+	case err != nil:
+		r.log.Error(ctx, "Repository.lockForChange: query failed", zap.Error(err))
+		return models.Subscription{}, err
+	case s.DeletedAt == nil && deleted:
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d is not deleted", models.ErrConflict, id)
+	case version > 0 && s.Version != version:
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d is at version %d", models.ErrStaleVersion, id, s.Version)
+	}
+	return s, nil
+}
+
+// change изменяет подписку id в одной транзакции: блокирует строку, проверяет версию и
+// состояние удаления (см. lockForChange), вызывает prepare (если задан) для сопутствующих
+// изменений, выполняет update с увеличением версии и пишет запись аудита с состоянием
+// до и после. Возвращает подписку после изменения.
+func (r *Repository) change(
+	ctx context.Context, op, action string, id, version int, deleted bool,
+	update squirrel.UpdateBuilder, prepare func(tx pgx.Tx, before models.Subscription) error,
+) (models.Subscription, error) {
+	sql, args, err := update.
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningSubscription).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository."+op+": builder failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	r.log.Debug(ctx, "Repository."+op+": executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	var after models.Subscription
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		before, err := r.lockForChange(ctx, tx, id, version, deleted)
+		if err != nil {
+			return err
+		}
+
+		if prepare != nil {
+			if err := prepare(tx, before); err != nil {
+				return err
+			}
+		}
+
+		if err := scanSubscription(tx.QueryRow(ctx, sql, args...), &after); err != nil {
+			r.log.Error(ctx, "Repository."+op+": query failed", zap.Error(err))
+			return mapError(err)
+		}
+		return r.writeAudit(ctx, tx, action, &before, &after)
+	})
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	return after, nil
+}
+
+// UpdateByID заменяет все поля подписки; сервис определяется по названию, как в Insert.
+// Теги и участники, равные nil в subscription (поле не передано), остаются прежними. Если subscription.Version больше нуля, запись изменяется только
+// в этой версии. После записи subscription содержит сохранённую строку.
+func (r *Repository) UpdateByID(ctx context.Context, subscription *models.Subscription) error {
+	update := r.query.
 		Update("subscriptions").
+		Set("service_id", serviceIDOf(subscription.Name)).
+		Set("name", serviceNameOf(subscription.Name)).
 		Set("price", subscription.Price).
+		Set("currency", subscription.Currency).
+		Set("user_id", subscription.UserID).
 		Set("start_date", subscription.StartDate).
 		Set("end_date", subscription.EndDate).
-		Where(squirrel.Eq{"name": subscription.Name, "user_id": subscription.UserID}).
-		ToSql()
+		Set("billing_period", subscription.BillingPeriod).
+		Set("interval_months", subscription.IntervalMonths).
+		Set("anchor_day", subscription.AnchorDay)
+
+	// Новая цена не переписывает прошлые месяцы, а начинает новый период цены
+	prepare := func(tx pgx.Tx, before models.Subscription) error {
+		if err := r.ensureService(ctx, tx, subscription.Name, subscription.Currency); err != nil {
+			return err
+		}
+		if subscription.Tags != nil {
+			if err := r.replaceTags(ctx, tx, subscription.ID, subscription.Tags); err != nil {
+				return err
+			}
+		}
+		if subscription.Members != nil {
+			if err := r.replaceMembers(ctx, tx, subscription.ID, subscription.Members); err != nil {
+				return err
+			}
+		}
+		if before.Price == subscription.Price {
+			return nil
+		}
+		return r.upsertPrice(ctx, tx, subscription.ID, models.PricePeriod{
+			EffectiveFrom: models.ChangeMonth(subscription.StartDate),
+			Price:         subscription.Price,
+		})
+	}
 
+	after, err := r.change(ctx, "UpdateByID", models.AuditUpdate, subscription.ID, subscription.Version, false, update, prepare)
 	if err != nil {
-		r.log.Error(ctx, "Repository.Update: builder failed", zap.Error(err))
 		return err
 	}
 
-	r.log.Debug(ctx, "Repository.Update: executing SQL",
-		zap.String("sql", sql),
-		zap.Any("args", args))
+	*subscription = after
+	return nil
+}
+
+// PatchByID обновляет только перечисленные в changes колонки подписки в версии version
+// и возвращает подписку после изменения. Ключи "tags" ([]string) и "members" ([]models.Member)
+// заменяют теги и участников подписки. Новое название связывает подписку с сервисом, как в Insert.
+func (r *Repository) PatchByID(ctx context.Context, id, version int, changes map[string]any) (models.Subscription, error) {
+	columns := make(map[string]any, len(changes)+1)
+	for k, v := range changes {
+		if k != "tags" && k != "members" {
+			columns[k] = v
+		}
+	}
+	name, renamed := changes["name"].(string)
+	if renamed {
+		columns["service_id"] = serviceIDOf(name)
+		columns["name"] = serviceNameOf(name)
+	}
+	update := r.query.
+		Update("subscriptions").
+		SetMap(columns)
+
+	prepare := func(tx pgx.Tx, before models.Subscription) error {
+		if renamed {
+			currency := before.Currency
+			if c, ok := changes["currency"].(string); ok {
+				currency = c
+			}
+			if err := r.ensureService(ctx, tx, name, currency); err != nil {
+				return err
+			}
+		}
+		if tags, ok := changes["tags"].([]string); ok {
+			if err := r.replaceTags(ctx, tx, id, tags); err != nil {
+				return err
+			}
+		}
+		if members, ok := changes["members"].([]models.Member); ok {
+			if err := r.replaceMembers(ctx, tx, id, members); err != nil {
+				return err
+			}
+		}
+
+		price, ok := changes["price"].(int)
+		if !ok || price == before.Price {
+			return nil
+		}
+		start := before.StartDate
+		if s, ok := changes["start_date"].(models.YearMonth); ok {
+			start = s
+		}
+		return r.upsertPrice(ctx, tx, id, models.PricePeriod{EffectiveFrom: models.ChangeMonth(start), Price: price})
+	}
 
-	_, err = r.db.Exec(ctx, sql, args...)
+	return r.change(ctx, "PatchByID", models.AuditUpdate, id, version, false, update, prepare)
+}
+
+// DeleteByID мягко удаляет подписку: строка остаётся для истории списаний, но скрывается
+// из выборок. Если version больше нуля, подписка удаляется только в этой версии.
+func (r *Repository) DeleteByID(ctx context.Context, id, version int) error {
+	update := r.query.
+		Update("subscriptions").
+		Set("deleted_at", squirrel.Expr("now()"))
+
+	_, err := r.change(ctx, "DeleteByID", models.AuditDelete, id, version, false, update, nil)
 	return err
 }
 
-func (r *Repository) Delete(ctx context.Context, name string, id uuid.UUID) error {
+// RestoreByID отменяет мягкое удаление подписки и возвращает её. Если version больше нуля,
+// подписка восстанавливается только в этой версии.
+func (r *Repository) RestoreByID(ctx context.Context, id, version int) (models.Subscription, error) {
+	update := r.query.
+		Update("subscriptions").
+		Set("deleted_at", nil)
+
+	return r.change(ctx, "RestoreByID", models.AuditRestore, id, version, true, update, nil)
+}
+
+// PurgeDeleted окончательно удаляет подписки, мягко удалённые раньше before, и возвращает их число.
+// История изменений удалённых подписок сохраняется и дополняется записью об очистке.
+func (r *Repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
 	sql, args, err := r.query.
 		Delete("subscriptions").
-		Where(squirrel.Eq{"name": name, "user_id": id}).
+		Where(squirrel.Lt{"deleted_at": before}).
+		Suffix(returningSubscription).
 		ToSql()
-
 	if err != nil {
-		r.log.Error(ctx, "Repository.Delete: builder failed", zap.Error(err))
-		return err
+		r.log.Error(ctx, "Repository.PurgeDeleted: builder failed", zap.Error(err))
+		return 0, err
 	}
 
-	r.log.Debug(ctx, "Repository.Delete: executing SQL",
+	r.log.Debug(ctx, "Repository.PurgeDeleted: executing SQL",
 		zap.String("sql", sql),
 		zap.Any("args", args))
 
-	_, err = r.db.Exec(ctx, sql, args...)
-	return err
+	var purged int64
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			r.log.Error(ctx, "Repository.PurgeDeleted: query failed", zap.Error(err))
+			return err
+		}
+
+		var subs []models.Subscription
+		for rows.Next() {
+			var s models.Subscription
+			if err := scanSubscription(rows, &s); err != nil {
+				rows.Close()
+				r.log.Error(ctx, "Repository.PurgeDeleted: scan failed", zap.Error(err))
+				return err
+			}
+			subs = append(subs, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for i := range subs {
+			if err := r.writeAudit(ctx, tx, models.AuditPurge, &subs[i], nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(subs))
+		return nil
+	})
+	return purged, err
 }
 
-func (r *Repository) SumPrice(ctx context.Context, name string, id uuid.UUID, startDate string, endDate string) (int, error) {
+// chargesJoin разворачивает каждую подписку в списания внутри запрошенного периода согласно
+// её периоду оплаты (см. функцию subscription_charges в миграциях) и подбирает для каждого
+// списания состояние подписки и цену, действовавшие на дату списания (см. subscription_status_periods
+// и subscription_prices), и курсы валюты подписки и валюты запроса. Каждое списание делится между
+// участниками подписки (member) пропорционально их долям; у подписки без участников единственный
+// участник — владелец с долей 1. Подписка без end_date считается активной до конца периода.
+// Если валюта запроса NULL, суммы остаются в валюте подписки.
+var chargesJoin = `CROSS JOIN (SELECT ?::date AS period_start, ?::date AS period_end, ?::text AS currency) AS period
+CROSS JOIN LATERAL subscription_charges(
+	s.start_date, s.end_date, s.billing_period, s.interval_months, s.anchor_day,
+	period.period_start, period.period_end
+) AS billed(charge_date)
+` + memberJoin + `
+CROSS JOIN LATERAL (SELECT COALESCE((
+	SELECT status FROM subscription_status_periods
+	WHERE subscription_id = s.id AND effective_from <= billed.charge_date
+	ORDER BY effective_from DESC LIMIT 1
+), s.status) AS status) AS billed_status
+CROSS JOIN LATERAL (SELECT COALESCE((
+	SELECT price FROM subscription_prices
+	WHERE subscription_id = s.id AND effective_from <= billed.charge_date
+	ORDER BY effective_from DESC LIMIT 1
+), s.price) AS price) AS billed_price
+CROSS JOIN LATERAL (` + rateLookup("s.currency") + `) AS rate_from
+CROSS JOIN LATERAL (` + rateLookup("period.currency") + `) AS rate_to
+CROSS JOIN LATERAL (SELECT CASE
+	WHEN period.currency IS NULL OR s.currency = period.currency THEN billed_price.price::numeric
+	ELSE billed_price.price * rate_from.rate / rate_to.rate
+END * member.ratio AS amount) AS charge`
+
+// memberJoin подставляет участников подписки s с долями ratio, в сумме равными 1;
+// у подписки без участников единственный участник — владелец.
+const memberJoin = `CROSS JOIN LATERAL (
+	SELECT m.user_id, m.share::numeric / SUM(m.share) OVER () AS ratio
+	FROM subscription_members AS m WHERE m.subscription_id = s.id
+	UNION ALL
+	SELECT s.user_id, 1 WHERE NOT EXISTS (SELECT 1 FROM subscription_members WHERE subscription_id = s.id)
+) AS member`
+
+// chargesCount считает списания без учёта того, на скольких участников делится каждое.
+const chargesCount = "COUNT(DISTINCT (s.id, billed.charge_date))::int"
+
+// rateLookup выбирает курс валюты, действующий на дату списания.
+// Если курса нет, rate равен NULL, и сумма такого списания тоже NULL.
+func rateLookup(currency string) string {
+	return fmt.Sprintf(`SELECT CASE WHEN %[1]s = '%[2]s' THEN 1::numeric ELSE (
+	SELECT rate FROM currency_rates
+	WHERE currency = %[1]s AND rate_date <= billed.charge_date
+	ORDER BY rate_date DESC LIMIT 1
+) END AS rate`, currency, models.BaseCurrency)
+}
+
+// chargesQuery отбирает списания подписок сервиса в периоде; с UserID — только доли этого
+// пользователя в подписках, где он участник.
+func (r *Repository) chargesQuery(columns []string, q models.ChargeQuery) squirrel.SelectBuilder {
+	var currency any
+	if q.Currency != "" {
+		currency = q.Currency
+	}
+
 	builder := r.query.
-		Select("COALESCE(SUM(price), 0)").
-		From("subscriptions").
-		Where(squirrel.Eq{"user_id": id}).
-		Where(squirrel.GtOrEq{"start_date": startDate})
+		Select(columns...).
+		From("subscriptions AS s").
+		JoinClause(chargesJoin, q.From, q.To, currency).
+		// Пробные, приостановленные и отменённые месяцы не оплачиваются
+		Where(squirrel.Eq{"billed_status.status": models.StatusActive})
 
-	if endDate != "" {
-		builder = builder.Where(squirrel.LtOrEq{"end_date": endDate})
+	if !q.IncludeDeleted {
+		builder = builder.Where(squirrel.Eq{"s.deleted_at": nil})
 	}
 
-	if name != "" {
-		builder = builder.Where(squirrel.Eq{"name": name})
+	if q.UserID != uuid.Nil {
+		builder = builder.Where(squirrel.Eq{"member.user_id": q.UserID})
 	}
 
+	if q.Name != "" {
+		builder = builder.Where(serviceByName("s.service_id", q.Name))
+	}
+
+	if q.Category != "" {
+		builder = builder.Where(inCategory("s.service_id", q.Category))
+	}
+
+	if q.ServiceID != 0 {
+		builder = builder.Where(squirrel.Eq{"s.service_id": q.ServiceID})
+	}
+
+	if len(q.Tags) > 0 {
+		builder = builder.Where(hasAllTags("s.id", q.Tags))
+	}
+
+	return builder
+}
+
+func (r *Repository) SumPrice(ctx context.Context, q models.CostQuery) (int, error) {
+	builder := r.chargesQuery([]string{
+		"ROUND(COALESCE(SUM(charge.amount), 0))::bigint",
+		"COUNT(*) FILTER (WHERE charge.amount IS NULL)",
+	}, q.Charges())
+
 	sql, args, err := builder.ToSql()
 	if err != nil {
 		r.log.Error(ctx, "Repository.SumPrice: builder failed", zap.Error(err))
@@ -169,7 +739,236 @@ func (r *Repository) SumPrice(ctx context.Context, name string, id uuid.UUID, st
 		zap.String("sql", sql),
 		zap.Any("args", args))
 
-	var sum int
-	err = r.db.QueryRow(ctx, sql, args...).Scan(&sum)
-	return sum, err
+	var sum, missing int
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&sum, &missing); err != nil {
+		return 0, err
+	}
+	if missing > 0 {
+		return 0, models.ErrMissingExchangeRate
+	}
+	return sum, nil
+}
+
+// SumPriceDetails возвращает стоимость каждой подписки и общую сумму из того же запроса.
+// Общая сумма округляется один раз от несокращённой суммы, как в SumPrice, а не складывается
+// из округлённых стоимостей подписок.
+func (r *Repository) SumPriceDetails(ctx context.Context, q models.CostQuery) ([]models.SubscriptionCost, int, error) {
+	builder := r.chargesQuery([]string{
+		"s.id", "s.name", "s.user_id", "s.price", "s.currency",
+		chargesCount,
+		"ROUND(COALESCE(SUM(charge.amount), 0))::bigint",
+		"ROUND(COALESCE(SUM(SUM(charge.amount)) OVER (), 0))::bigint",
+		"COUNT(*) FILTER (WHERE charge.amount IS NULL)",
+	}, q.Charges()).
+		GroupBy("s.id").
+		OrderBy("s.id")
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SumPriceDetails: builder failed", zap.Error(err))
+		return nil, 0, err
+	}
+	r.log.Debug(ctx, "Repository.SumPriceDetails: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SumPriceDetails: query failed", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		costs []models.SubscriptionCost
+		total int
+	)
+	for rows.Next() {
+		var c models.SubscriptionCost
+		var missing int
+		if err := rows.Scan(&c.SubscriptionID, &c.Name, &c.UserID, &c.Price, &c.Currency, &c.Charges, &c.Cost, &total, &missing); err != nil {
+			r.log.Error(ctx, "Repository.SumPriceDetails: scan failed", zap.Error(err))
+			return nil, 0, err
+		}
+		if missing > 0 {
+			return nil, 0, models.ErrMissingExchangeRate
+		}
+		costs = append(costs, c)
+	}
+
+	return costs, total, rows.Err()
+}
+
+// costGroupKeys — выражение ключа и соединения для группировок SumPriceGroups.
+var costGroupKeys = map[string]struct{ key, join string }{
+	models.GroupByCategory: {"sv.category", "LEFT JOIN services AS sv ON sv.id = s.service_id"},
+	models.GroupByTag: {"t.name", `LEFT JOIN subscription_tags AS st ON st.subscription_id = s.id
+LEFT JOIN tags AS t ON t.id = st.tag_id`},
+}
+
+// SumPriceGroups считает стоимость подписок за период в разрезе категорий сервисов или тегов.
+// Списание подписки с несколькими тегами входит в группу каждого тега. Подписки без категории
+// (без тегов) попадают в группу с ключом nil.
+func (r *Repository) SumPriceGroups(ctx context.Context, q models.CostQuery, groupBy string) ([]models.CostGroup, error) {
+	group, ok := costGroupKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown group_by %q", models.ErrValidation, groupBy)
+	}
+
+	builder := r.chargesQuery([]string{
+		group.key,
+		"ROUND(COALESCE(SUM(charge.amount), 0))::bigint",
+		chargesCount,
+		"COUNT(*) FILTER (WHERE charge.amount IS NULL)",
+	}, q.Charges()).
+		JoinClause(group.join).
+		GroupBy(group.key).
+		OrderBy("2 DESC", group.key+" NULLS LAST")
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SumPriceGroups: builder failed", zap.Error(err))
+		return nil, err
+	}
+	r.log.Debug(ctx, "Repository.SumPriceGroups: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SumPriceGroups: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.CostGroup{}
+	for rows.Next() {
+		var g models.CostGroup
+		var missing int
+		if err := rows.Scan(&g.Key, &g.Total, &g.Charges, &missing); err != nil {
+			r.log.Error(ctx, "Repository.SumPriceGroups: scan failed", zap.Error(err))
+			return nil, err
+		}
+		if missing > 0 {
+			return nil, models.ErrMissingExchangeRate
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *Repository) SelectCharges(ctx context.Context, q models.ChargeQuery) ([]models.Charge, error) {
+	builder := r.chargesQuery([]string{
+		"s.id", "s.name", "member.user_id", "billed.charge_date",
+		"ROUND(charge.amount)::bigint",
+		"COALESCE(period.currency, s.currency)",
+	}, q).
+		OrderBy("billed.charge_date", "s.id")
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectCharges: builder failed", zap.Error(err))
+		return nil, err
+	}
+	r.log.Debug(ctx, "Repository.SelectCharges: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.SelectCharges: query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var charges []models.Charge
+	for rows.Next() {
+		var c models.Charge
+		var amount *int
+		if err := rows.Scan(&c.SubscriptionID, &c.Name, &c.UserID, &c.Date, &amount, &c.Currency); err != nil {
+			r.log.Error(ctx, "Repository.SelectCharges: scan failed", zap.Error(err))
+			return nil, err
+		}
+		if amount == nil {
+			return nil, models.ErrMissingExchangeRate
+		}
+		c.Amount = *amount
+		charges = append(charges, c)
+	}
+
+	return charges, rows.Err()
+}
+
+// Биты GROUPING(bucket.month, s.name, s.user_id): установлен бит колонки, по которой строка не сгруппирована.
+const (
+	groupedByMonth   = 0b011
+	groupedByService = 0b101
+	groupedByUser    = 0b110
+	groupedTotal     = 0b111
+)
+
+// Analytics считает суммы списаний за период сразу в нескольких разрезах одним запросом через GROUPING SETS.
+func (r *Repository) Analytics(ctx context.Context, q models.CostQuery) (models.Analytics, error) {
+	builder := r.chargesQuery([]string{
+		"GROUPING(bucket.month, s.name, member.user_id)",
+		"bucket.month", "s.name", "member.user_id",
+		"ROUND(COALESCE(SUM(charge.amount), 0))::bigint",
+		chargesCount,
+		"COUNT(*) FILTER (WHERE charge.amount IS NULL)",
+	}, q.Charges()).
+		JoinClause("CROSS JOIN LATERAL (SELECT date_trunc('month', billed.charge_date)::date AS month) AS bucket").
+		GroupBy("GROUPING SETS ((bucket.month), (s.name), (member.user_id), ())").
+		OrderBy("1", "bucket.month", "5 DESC")
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.Analytics: builder failed", zap.Error(err))
+		return models.Analytics{}, err
+	}
+	r.log.Debug(ctx, "Repository.Analytics: executing SQL",
+		zap.String("sql", sql),
+		zap.Any("args", args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		r.log.Error(ctx, "Repository.Analytics: query failed", zap.Error(err))
+		return models.Analytics{}, err
+	}
+	defer rows.Close()
+
+	a := models.Analytics{
+		Currency:  q.Currency,
+		ByMonth:   []models.MonthTotal{},
+		ByService: []models.ServiceTotal{},
+		ByUser:    []models.UserTotal{},
+	}
+	for rows.Next() {
+		var (
+			grouping, total, charges, missing int
+			month                             *models.YearMonth
+			name                              *string
+			userID                            *uuid.UUID
+		)
+		if err := rows.Scan(&grouping, &month, &name, &userID, &total, &charges, &missing); err != nil {
+			r.log.Error(ctx, "Repository.Analytics: scan failed", zap.Error(err))
+			return models.Analytics{}, err
+		}
+		if missing > 0 {
+			return models.Analytics{}, models.ErrMissingExchangeRate
+		}
+
+		switch grouping {
+		case groupedByMonth:
+			a.ByMonth = append(a.ByMonth, models.MonthTotal{Month: *month, Total: total, Charges: charges})
+		case groupedByService:
+			a.ByService = append(a.ByService, models.ServiceTotal{Name: *name, Total: total, Charges: charges})
+		case groupedByUser:
+			a.ByUser = append(a.ByUser, models.UserTotal{UserID: *userID, Total: total, Charges: charges})
+		case groupedTotal:
+			a.Total = total
+		}
+	}
+
+	return a, rows.Err()
 }
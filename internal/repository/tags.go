@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// tagsColumn — теги подписки по алфавиту; выражение ссылается на таблицу subscriptions без псевдонима.
+const tagsColumn = `ARRAY(
+	SELECT t.name FROM subscription_tags AS st JOIN tags AS t ON t.id = st.tag_id
+	WHERE st.subscription_id = subscriptions.id ORDER BY t.name
+) AS tags`
+
+// hasAllTags отбирает строки, у которых подписка в колонке column помечена всеми тегами tags.
+func hasAllTags(column string, tags []string) squirrel.Sqlizer {
+	return squirrel.Expr(column+` IN (
+	SELECT st.subscription_id FROM subscription_tags AS st JOIN tags AS t ON t.id = st.tag_id
+	WHERE t.name = ANY(?)
+	GROUP BY st.subscription_id
+	HAVING COUNT(*) = ?
+)`, tags, len(tags))
+}
+
+// replaceTags заменяет теги подписки id на tags, недостающие теги создаются.
+func (r *Repository) replaceTags(ctx context.Context, tx pgx.Tx, id int, tags []string) error {
+	deleteSQL, deleteArgs, err := r.query.
+		Delete("subscription_tags").
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		r.log.Error(ctx, "Repository.replaceTags: builder failed", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+		r.log.Error(ctx, "Repository.replaceTags: delete failed", zap.Error(err))
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	r.log.Debug(ctx, "Repository.replaceTags: executing SQL",
+		zap.Int("subscription_id", id),
+		zap.Strings("tags", tags))
+
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING",
+		tags,
+	); err != nil {
+		r.log.Error(ctx, "Repository.replaceTags: insert tags failed", zap.Error(err))
+		return mapError(err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO subscription_tags (subscription_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)",
+		id, tags,
+	); err != nil {
+		r.log.Error(ctx, "Repository.replaceTags: insert links failed", zap.Error(err))
+		return mapError(err)
+	}
+	return nil
+}
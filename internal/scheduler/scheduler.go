@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"effective_mobile/pkg/logger"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// releaseTimeout ограничивает снятие блокировки при остановке, когда контекст Run уже отменён.
+const releaseTimeout = 5 * time.Second
+
+// Job — периодическая задача: Run вызывается сразу после получения лидерства и затем раз в Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler выполняет задачи только на одной реплике приложения — лидере. Лидер выбирается
+// сессионной advisory-блокировкой Postgres: её держит выделенное соединение пула, пока оно живо.
+// Остальные реплики раз в retry пытаются занять блокировку и становятся лидером, когда прежний
+// лидер остановился или потерял соединение с базой.
+type Scheduler struct {
+	db      *pgxpool.Pool
+	lockKey int64
+	retry   time.Duration
+	jobs    []Job
+	log     logger.Logger
+}
+
+func New(db *pgxpool.Pool, lockKey int64, retry time.Duration, env string) *Scheduler {
+	return &Scheduler{
+		db:      db,
+		lockKey: lockKey,
+		retry:   retry,
+		log:     logger.NewLogger(env),
+	}
+}
+
+// Add регистрирует задачу; вызывается до Run.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Run борется за лидерство и, пока оно есть, выполняет задачи. Возвращается после отмены ctx,
+// когда все задачи завершились и блокировка снята.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		if err := s.lead(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error(ctx, "Scheduler: leadership lost", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retry):
+		}
+	}
+}
+
+// lead пытается занять блокировку и, если это удалось, выполняет задачи до отмены ctx
+// или потери соединения, которое держит блокировку.
+func (s *Scheduler) lead(ctx context.Context) error {
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", s.lockKey).Scan(&locked); err != nil {
+		conn.Release()
+		return err
+	}
+	if !locked {
+		conn.Release()
+		s.log.Debug(ctx, "Scheduler: another replica is the leader")
+		return nil
+	}
+	s.log.Info(ctx, "Scheduler: became the leader", zap.Int("jobs", len(s.jobs)))
+
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runJob(jobsCtx, job)
+		}()
+	}
+
+	err = s.hold(ctx, conn)
+	stopJobs()
+	wg.Wait()
+	s.release(conn, err != nil)
+	return err
+}
+
+// hold проверяет соединение с блокировкой раз в retry. Пока сессия жива, блокировка за ней;
+// ошибка проверки означает, что блокировка могла перейти к другой реплике.
+func (s *Scheduler) hold(ctx context.Context, conn *pgxpool.Conn) error {
+	ticker := time.NewTicker(s.retry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := conn.Ping(ctx); err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+}
+
+// release снимает блокировку и возвращает соединение в пул. Если соединение потеряно или снять
+// блокировку не удалось, соединение закрывается: сессионная блокировка снимается вместе с сессией.
+func (s *Scheduler) release(conn *pgxpool.Conn, lost bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	if !lost {
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", s.lockKey); err != nil {
+			s.log.Error(ctx, "Scheduler: unlock failed", zap.Error(err))
+			lost = true
+		}
+	}
+	if lost {
+		_ = conn.Conn().Close(ctx)
+	}
+	conn.Release()
+	s.log.Info(ctx, "Scheduler: leadership released")
+}
+
+// runJob выполняет задачу сразу и затем раз в job.Interval, пока не отменён ctx.
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		started := time.Now()
+		if err := job.Run(ctx); err != nil {
+			// Следующая попытка будет на следующем тике
+			if ctx.Err() == nil {
+				s.log.Error(ctx, "Scheduler: job failed", zap.String("job", job.Name), zap.Error(err))
+			}
+		} else {
+			s.log.Debug(ctx, "Scheduler: job done",
+				zap.String("job", job.Name),
+				zap.Duration("took", time.Since(started)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BudgetRepository interface {
+	SelectBudgets(ctx context.Context, userID uuid.UUID) ([]models.Budget, error)
+	SelectAllBudgets(ctx context.Context) ([]models.Budget, error)
+	InsertBudget(ctx context.Context, budget *models.Budget) error
+	UpdateBudget(ctx context.Context, budget *models.Budget) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID, id int) error
+	SelectServiceByID(ctx context.Context, id int) (models.Service, error)
+	SumPrice(ctx context.Context, q models.CostQuery) (int, error)
+	RecordBudgetMonth(ctx context.Context, b models.Budget, m models.BudgetMonth) (*models.BudgetEvent, error)
+	SelectBudgetEvents(ctx context.Context, userID *uuid.UUID, after int64, limit int) ([]models.BudgetEvent, error)
+}
+
+type BudgetService struct {
+	repo BudgetRepository
+	log  logger.Logger
+}
+
+func NewBudgetService(repository BudgetRepository, env string) *BudgetService {
+	return &BudgetService{
+		repo: repository,
+		log:  logger.NewLogger(env),
+	}
+}
+
+func (s *BudgetService) Select(ctx context.Context, userID uuid.UUID) ([]models.Budget, error) {
+	s.log.Debug(ctx, "Service.SelectBudgets called", zap.String("user_id", userID.String()))
+
+	budgets, err := s.repo.SelectBudgets(ctx, userID)
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectBudgets error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SelectBudgets result", zap.Int("budgets_count", len(budgets)))
+	}
+	return budgets, err
+}
+
+func (s *BudgetService) Insert(ctx context.Context, budget *models.Budget) error {
+	s.log.Debug(ctx, "Service.InsertBudget called", zap.Any("budget", budget))
+	if err := s.prepare(ctx, budget); err != nil {
+		return err
+	}
+
+	err := s.repo.InsertBudget(ctx, budget)
+	if err != nil {
+		s.log.Error(ctx, "Service.InsertBudget error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.InsertBudget successful", zap.Int("budget_id", budget.ID))
+	}
+	return err
+}
+
+// UpdateByID заменяет область, лимит и валюту бюджета budget.ID пользователя budget.UserID.
+func (s *BudgetService) UpdateByID(ctx context.Context, budget *models.Budget) error {
+	s.log.Debug(ctx, "Service.UpdateBudget called", zap.Any("budget", budget))
+	if err := s.prepare(ctx, budget); err != nil {
+		return err
+	}
+
+	err := s.repo.UpdateBudget(ctx, budget)
+	if err != nil {
+		s.log.Error(ctx, "Service.UpdateBudget error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.UpdateBudget successful")
+	}
+	return err
+}
+
+func (s *BudgetService) DeleteByID(ctx context.Context, userID uuid.UUID, id int) error {
+	s.log.Debug(ctx, "Service.DeleteBudget called", zap.String("user_id", userID.String()), zap.Int("id", id))
+
+	err := s.repo.DeleteBudget(ctx, userID, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.DeleteBudget error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.DeleteBudget successful")
+	}
+	return err
+}
+
+// Status считает для каждого бюджета пользователя прогноз расходов на текущий и следующий месяцы
+// по тем же правилам, что и SumPrice, с долей пользователя в совместных подписках.
+// Status только читает данные: события переходов через лимит записывает Check.
+func (s *BudgetService) Status(ctx context.Context, userID uuid.UUID) ([]models.BudgetStatus, error) {
+	s.log.Debug(ctx, "Service.BudgetStatus called", zap.String("user_id", userID.String()))
+
+	budgets, err := s.repo.SelectBudgets(ctx, userID)
+	if err != nil {
+		s.log.Error(ctx, "Service.BudgetStatus select error", zap.Error(err))
+		return nil, err
+	}
+
+	statuses := make([]models.BudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		status := models.BudgetStatus{Budget: b}
+		for _, month := range budgetMonths() {
+			m, err := s.projectMonth(ctx, b, month)
+			if err != nil {
+				return nil, err
+			}
+			status.Months = append(status.Months, m)
+		}
+		statuses = append(statuses, status)
+	}
+
+	s.log.Debug(ctx, "Service.BudgetStatus result", zap.Int("budgets_count", len(statuses)))
+	return statuses, nil
+}
+
+// Check пересчитывает прогнозы всех бюджетов на текущий и следующий месяцы и записывает
+// переходы через лимит событиями models.BudgetEvent. Запускается планировщиком, поэтому
+// переход, вызванный изменением подписки, цены или участников, попадает в журнал без запросов
+// клиентов. Месяц бюджета, который не удалось посчитать (например, нет курса валюты), проверяется
+// снова при следующем запуске; ошибка Check называет число таких месяцев.
+func (s *BudgetService) Check(ctx context.Context) error {
+	s.log.Debug(ctx, "Service.BudgetCheck called")
+
+	budgets, err := s.repo.SelectAllBudgets(ctx)
+	if err != nil {
+		s.log.Error(ctx, "Service.BudgetCheck select error", zap.Error(err))
+		return err
+	}
+
+	var recorded, failed int
+	for _, b := range budgets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, month := range budgetMonths() {
+			m, err := s.projectMonth(ctx, b, month)
+			if err != nil {
+				failed++
+				continue
+			}
+
+			event, err := s.repo.RecordBudgetMonth(ctx, b, m)
+			if err != nil {
+				s.log.Error(ctx, "Service.BudgetCheck record error", zap.Int("budget_id", b.ID), zap.Error(err))
+				failed++
+				continue
+			}
+			if event != nil {
+				recorded++
+				s.log.Info(ctx, "Service.BudgetCheck: budget "+event.Kind,
+					zap.Int("budget_id", b.ID),
+					zap.String("month", month.String()),
+					zap.Int("projected", m.Projected),
+					zap.Int("limit", b.Limit),
+				)
+			}
+		}
+	}
+
+	s.log.Info(ctx, "Service.BudgetCheck done",
+		zap.Int("budgets_count", len(budgets)),
+		zap.Int("events", recorded),
+		zap.Int("failed", failed))
+	if failed > 0 {
+		return fmt.Errorf("%d budget months not checked", failed)
+	}
+	return nil
+}
+
+// budgetMonths — месяцы, на которые считается прогноз бюджета: текущий и следующий.
+func budgetMonths() []models.YearMonth {
+	current := models.YearMonthOf(time.Now())
+	return []models.YearMonth{current, models.YearMonthOf(current.Time().AddDate(0, 1, 0))}
+}
+
+// projectMonth считает прогноз бюджета b на месяц month.
+func (s *BudgetService) projectMonth(ctx context.Context, b models.Budget, month models.YearMonth) (models.BudgetMonth, error) {
+	q := models.CostQuery{
+		UserID:    b.UserID,
+		StartDate: month,
+		EndDate:   month,
+		Currency:  b.Currency,
+	}
+	if b.Category != nil {
+		q.Category = *b.Category
+	}
+	if b.ServiceID != nil {
+		q.ServiceID = *b.ServiceID
+	}
+
+	projected, err := s.repo.SumPrice(ctx, q)
+	if err != nil {
+		s.log.Error(ctx, "Service.projectMonth error", zap.Int("budget_id", b.ID), zap.Error(err))
+		return models.BudgetMonth{}, err
+	}
+
+	return models.BudgetMonth{
+		Month:      month,
+		Projected:  projected,
+		Limit:      b.Limit,
+		Remaining:  b.Limit - projected,
+		OverBudget: projected > b.Limit,
+	}, nil
+}
+
+// Events возвращает до limit событий бюджетов пользователя после события after.
+func (s *BudgetService) Events(ctx context.Context, userID uuid.UUID, after int64, limit int) ([]models.BudgetEvent, error) {
+	s.log.Debug(ctx, "Service.BudgetEvents called",
+		zap.String("user_id", userID.String()),
+		zap.Int64("after", after),
+		zap.Int("limit", limit),
+	)
+	return s.events(ctx, &userID, after, limit)
+}
+
+// AllEvents возвращает до limit событий бюджетов всех пользователей после события after.
+func (s *BudgetService) AllEvents(ctx context.Context, after int64, limit int) ([]models.BudgetEvent, error) {
+	s.log.Debug(ctx, "Service.AllBudgetEvents called", zap.Int64("after", after), zap.Int("limit", limit))
+	return s.events(ctx, nil, after, limit)
+}
+
+func (s *BudgetService) events(ctx context.Context, userID *uuid.UUID, after int64, limit int) ([]models.BudgetEvent, error) {
+	events, err := s.repo.SelectBudgetEvents(ctx, userID, after, limit)
+	if err != nil {
+		s.log.Error(ctx, "Service.BudgetEvents error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.BudgetEvents result", zap.Int("events_count", len(events)))
+	}
+	return events, err
+}
+
+// prepare нормализует и проверяет бюджет перед записью; service_id должен ссылаться на сервис каталога.
+func (s *BudgetService) prepare(ctx context.Context, budget *models.Budget) error {
+	normalizeBudget(budget)
+	if err := validateBudget(*budget); err != nil {
+		s.log.Debug(ctx, "Service.prepare validation failed", zap.Error(err))
+		return err
+	}
+
+	if budget.ServiceID != nil {
+		_, err := s.repo.SelectServiceByID(ctx, *budget.ServiceID)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return &models.ValidationError{Violations: []models.FieldViolation{
+				{Field: "service_id", Message: "unknown service"},
+			}}
+		case err != nil:
+			s.log.Error(ctx, "Service.prepare select service error", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeBudget подставляет валюту по умолчанию и убирает пустую категорию.
+func normalizeBudget(budget *models.Budget) {
+	budget.Currency = models.NormalizeCurrency(budget.Currency)
+	if budget.Category != nil {
+		if category := strings.TrimSpace(*budget.Category); category != "" {
+			budget.Category = &category
+		} else {
+			budget.Category = nil
+		}
+	}
+}
+
+func validateBudget(budget models.Budget) error {
+	var violations []models.FieldViolation
+	add := func(field, msg string) {
+		violations = append(violations, models.FieldViolation{Field: field, Message: msg})
+	}
+
+	if msg := checkPrice(budget.Limit); msg != "" {
+		add("monthly_limit", msg)
+	} else if budget.Limit == 0 {
+		add("monthly_limit", "must be positive")
+	}
+	if !models.IsSupportedCurrency(budget.Currency) {
+		add("currency", fmt.Sprintf("unsupported currency %q", budget.Currency))
+	}
+	if budget.Category != nil && utf8.RuneCountInString(*budget.Category) > maxNameLength {
+		add("category", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	}
+	if budget.Category != nil && budget.ServiceID != nil {
+		add("service_id", "cannot be combined with category")
+	}
+
+	if len(violations) > 0 {
+		return &models.ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+type CatalogRepository interface {
+	SelectServices(ctx context.Context, category string) ([]models.Service, error)
+	SelectServiceByID(ctx context.Context, id int) (models.Service, error)
+	ResolveService(ctx context.Context, name string) (models.Service, error)
+	InsertService(ctx context.Context, service *models.Service) error
+	UpdateService(ctx context.Context, service *models.Service) error
+	DeleteService(ctx context.Context, id int) error
+}
+
+type CatalogService struct {
+	repo CatalogRepository
+	log  logger.Logger
+}
+
+func NewCatalogService(repository CatalogRepository, env string) *CatalogService {
+	return &CatalogService{
+		repo: repository,
+		log:  logger.NewLogger(env),
+	}
+}
+
+func (s *CatalogService) Select(ctx context.Context, category string) ([]models.Service, error) {
+	s.log.Debug(ctx, "Service.SelectServices called", zap.String("category", category))
+
+	services, err := s.repo.SelectServices(ctx, strings.TrimSpace(category))
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectServices error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SelectServices result", zap.Int("services_count", len(services)))
+	}
+	return services, err
+}
+
+func (s *CatalogService) SelectByID(ctx context.Context, id int) (models.Service, error) {
+	s.log.Debug(ctx, "Service.SelectServiceByID called", zap.Int("id", id))
+
+	service, err := s.repo.SelectServiceByID(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectServiceByID error", zap.Error(err))
+	}
+	return service, err
+}
+
+func (s *CatalogService) Insert(ctx context.Context, service *models.Service) error {
+	s.log.Debug(ctx, "Service.InsertService called", zap.Any("service", service))
+	if err := s.prepare(ctx, service); err != nil {
+		return err
+	}
+
+	err := s.repo.InsertService(ctx, service)
+	if err != nil {
+		s.log.Error(ctx, "Service.InsertService error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.InsertService successful", zap.Int("service_id", service.ID))
+	}
+	return err
+}
+
+// UpdateByID заменяет поля и псевдонимы сервиса service.ID. Новое название переходит
+// во все подписки этого сервиса.
+func (s *CatalogService) UpdateByID(ctx context.Context, service *models.Service) error {
+	s.log.Debug(ctx, "Service.UpdateService called", zap.Any("service", service))
+	if err := s.prepare(ctx, service); err != nil {
+		return err
+	}
+
+	err := s.repo.UpdateService(ctx, service)
+	if err != nil {
+		s.log.Error(ctx, "Service.UpdateService error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.UpdateService successful")
+	}
+	return err
+}
+
+// DeleteByID удаляет сервис, на который не ссылается ни одна подписка.
+func (s *CatalogService) DeleteByID(ctx context.Context, id int) error {
+	s.log.Debug(ctx, "Service.DeleteService called", zap.Int("id", id))
+
+	err := s.repo.DeleteService(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.DeleteService error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.DeleteService successful")
+	}
+	return err
+}
+
+// prepare нормализует и проверяет сервис перед записью. Название и псевдонимы вместе образуют
+// одно пространство имён: ни одно из них не может уже называть другой сервис. Гарантирует это
+// база (service_names), а проверка здесь лишь даёт понятное сообщение о том, какое имя занято.
+func (s *CatalogService) prepare(ctx context.Context, service *models.Service) error {
+	normalizeService(service)
+	if err := validateService(*service); err != nil {
+		s.log.Debug(ctx, "Service.prepare validation failed", zap.Error(err))
+		return err
+	}
+
+	for _, name := range append([]string{service.Name}, service.Aliases...) {
+		other, err := s.repo.ResolveService(ctx, name)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			continue
+		case err != nil:
+			s.log.Error(ctx, "Service.prepare resolve error", zap.Error(err))
+			return err
+		case other.ID != service.ID:
+			return fmt.Errorf("%w: %q already names service %d", models.ErrConflict, name, other.ID)
+		}
+	}
+	return nil
+}
+
+// normalizeService обрезает пробелы, подставляет валюту по умолчанию и убирает повторы
+// псевдонимов и совпадения с названием без учёта регистра.
+func normalizeService(service *models.Service) {
+	service.Name = strings.TrimSpace(service.Name)
+	service.Currency = models.NormalizeCurrency(service.Currency)
+	if service.Category != nil {
+		if category := strings.TrimSpace(*service.Category); category != "" {
+			service.Category = &category
+		} else {
+			service.Category = nil
+		}
+	}
+
+	seen := map[string]bool{strings.ToLower(service.Name): true}
+	aliases := make([]string, 0, len(service.Aliases))
+	for _, alias := range service.Aliases {
+		alias = strings.TrimSpace(alias)
+		if key := strings.ToLower(alias); !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	service.Aliases = aliases
+}
+
+func validateService(service models.Service) error {
+	var violations []models.FieldViolation
+	add := func(field, msg string) {
+		violations = append(violations, models.FieldViolation{Field: field, Message: msg})
+	}
+
+	if msg := checkServiceName(service.Name); msg != "" {
+		add("name", msg)
+	}
+	for i, alias := range service.Aliases {
+		if msg := checkServiceName(alias); msg != "" {
+			add(fmt.Sprintf("aliases[%d]", i), msg)
+		}
+	}
+	if service.Category != nil && utf8.RuneCountInString(*service.Category) > maxNameLength {
+		add("category", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	}
+	if service.DefaultPrice != nil {
+		if msg := checkPrice(*service.DefaultPrice); msg != "" {
+			add("default_price", msg)
+		}
+	}
+	if !models.IsSupportedCurrency(service.Currency) {
+		add("currency", fmt.Sprintf("unsupported currency %q", service.Currency))
+	}
+
+	if len(violations) > 0 {
+		return &models.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func checkServiceName(name string) string {
+	switch {
+	case name == "":
+		return "is required"
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return fmt.Sprintf("must be at most %d characters", maxNameLength)
+	}
+	return ""
+}
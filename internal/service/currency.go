@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidRate оборачивает ошибки проверки загружаемых курсов.
+var ErrInvalidRate = errors.New("invalid currency rate")
+
+// Границы курса, которые помещаются в колонку currency_rates.rate NUMERIC(18, 6)
+// без округления до нуля и без переполнения.
+const (
+	minRate = 0.000001
+	maxRate = 1e12
+)
+
+type CurrencyRateRepository interface {
+	UpsertRates(ctx context.Context, rates []models.CurrencyRate) error
+	SelectRates(ctx context.Context, currency string) ([]models.CurrencyRate, error)
+}
+
+type CurrencyService struct {
+	repo CurrencyRateRepository
+	log  logger.Logger
+}
+
+func NewCurrencyService(repository CurrencyRateRepository, env string) *CurrencyService {
+	return &CurrencyService{
+		repo: repository,
+		log:  logger.NewLogger(env),
+	}
+}
+
+// LoadRates проверяет и сохраняет курсы. Курс базовой валюты всегда равен 1 и не хранится.
+func (s *CurrencyService) LoadRates(ctx context.Context, rates []models.CurrencyRate) error {
+	s.log.Debug(ctx, "Service.LoadRates called", zap.Int("rates_count", len(rates)))
+
+	for i := range rates {
+		rates[i].Currency = models.NormalizeCurrency(rates[i].Currency)
+		if err := validateRate(rates[i]); err != nil {
+			return fmt.Errorf("rate #%d: %w", i+1, err)
+		}
+	}
+
+	err := s.repo.UpsertRates(ctx, rates)
+	if err != nil {
+		s.log.Error(ctx, "Service.LoadRates error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.LoadRates successful")
+	}
+	return err
+}
+
+func (s *CurrencyService) SelectRates(ctx context.Context, currency string) ([]models.CurrencyRate, error) {
+	s.log.Debug(ctx, "Service.SelectRates called", zap.String("currency", currency))
+
+	rates, err := s.repo.SelectRates(ctx, currency)
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectRates error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SelectRates result", zap.Int("rates_count", len(rates)))
+	}
+	return rates, err
+}
+
+func validateRate(rate models.CurrencyRate) error {
+	switch {
+	case !models.IsSupportedCurrency(rate.Currency):
+		return fmt.Errorf("%w: unsupported currency %q", ErrInvalidRate, rate.Currency)
+	case rate.Currency == models.BaseCurrency:
+		return fmt.Errorf("%w: rate of the base currency %s is always 1", ErrInvalidRate, models.BaseCurrency)
+	case rate.Date.IsZero():
+		return fmt.Errorf("%w: date is required", ErrInvalidRate)
+	case math.IsNaN(rate.Rate) || math.IsInf(rate.Rate, 0):
+		return fmt.Errorf("%w: rate must be a finite number", ErrInvalidRate)
+	case rate.Rate <= 0:
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidRate)
+	case rate.Rate < minRate:
+		return fmt.Errorf("%w: rate must be at least %g", ErrInvalidRate, minRate)
+	case rate.Rate >= maxRate:
+		return fmt.Errorf("%w: rate must be less than %g", ErrInvalidRate, maxRate)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"effective_mobile/internal/models"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValidateRate(t *testing.T) {
+	day := models.DateOf(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		rate    float64
+		wantErr bool
+	}{
+		{"regular", 92.5, false},
+		{"smallest stored", 0.000001, false},
+		{"NaN", math.NaN(), true},
+		{"infinity", math.Inf(1), true},
+		{"zero", 0, true},
+		{"negative", -1, true},
+		{"rounds to zero", 0.0000004, true},
+		{"overflows the column", 1e12, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRate(models.CurrencyRate{Currency: "USD", Date: day, Rate: tt.rate})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validateRate(%v) error = %v, wantErr %v", tt.rate, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRate) {
+				t.Fatalf("validateRate(%v) error = %v, want ErrInvalidRate", tt.rate, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"effective_mobile/pkg/logger"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ReminderRepository interface {
+	SelectReminders(ctx context.Context, from, to models.Date) ([]models.Reminder, error)
+	MarkReminderSent(ctx context.Context, rem models.Reminder) error
+	SelectReminderRun(ctx context.Context) (models.Date, error)
+	SaveReminderRun(ctx context.Context, day models.Date) error
+}
+
+// Notifier доставляет напоминание пользователю: письмом, push-уведомлением, в очередь и т.п.
+// Ошибка означает, что напоминание не доставлено и будет отправлено повторно при следующем запуске.
+type Notifier interface {
+	Notify(ctx context.Context, rem models.Reminder) error
+}
+
+type ReminderService struct {
+	repo     ReminderRepository
+	notifier Notifier
+	days     int
+	lookback int
+	log      logger.Logger
+}
+
+// NewReminderService создаёт сервис напоминаний о событиях подписок в ближайшие days дней,
+// который досылает пропущенные напоминания не более чем за lookback прошедших дней.
+func NewReminderService(repository ReminderRepository, notifier Notifier, days, lookback int, env string) *ReminderService {
+	return &ReminderService{
+		repo:     repository,
+		notifier: notifier,
+		days:     days,
+		lookback: lookback,
+		log:      logger.NewLogger(env),
+	}
+}
+
+// Run отправляет ещё не отправленные напоминания о списаниях и окончании подписок по days дней
+// вперёд. Окно начинается с дня последнего запуска без неудач, но не раньше чем за lookback дней
+// до сегодняшнего: напоминания, пропущенные, пока планировщик не работал, досылаются с опозданием,
+// а напоминание, которое не доставляется никогда, не растягивает окно бесконечно.
+// Если хотя бы одно напоминание не доставлено или не отмечено, Run возвращает ошибку с их числом.
+func (s *ReminderService) Run(ctx context.Context) error {
+	today := models.DateOf(time.Now())
+	to := models.DateOf(today.AddDate(0, 0, s.days))
+
+	from, err := s.repo.SelectReminderRun(ctx)
+	if err != nil {
+		s.log.Error(ctx, "Service.Reminders last run error", zap.Error(err))
+		return err
+	}
+	earliest := models.DateOf(today.AddDate(0, 0, -s.lookback))
+	switch {
+	case from.IsZero() || from.After(today.Time):
+		from = today
+	case from.Before(earliest.Time):
+		from = earliest
+	}
+	s.log.Debug(ctx, "Service.Reminders called", zap.String("from", from.String()), zap.String("to", to.String()))
+
+	reminders, err := s.repo.SelectReminders(ctx, from, to)
+	if err != nil {
+		s.log.Error(ctx, "Service.Reminders select error", zap.Error(err))
+		return err
+	}
+
+	var sent, failed int
+	for _, rem := range reminders {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.notifier.Notify(ctx, rem); err != nil {
+			s.log.Error(ctx, "Service.Reminders notify error",
+				zap.Int("subscription_id", rem.SubscriptionID),
+				zap.String("user_id", rem.UserID.String()),
+				zap.String("kind", rem.Kind),
+				zap.Error(err),
+			)
+			failed++
+			continue
+		}
+		// Если отметка не записалась, напоминание придёт повторно — это лучше, чем потерять его
+		if err := s.repo.MarkReminderSent(ctx, rem); err != nil {
+			s.log.Error(ctx, "Service.Reminders mark error", zap.Error(err))
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	s.log.Info(ctx, "Service.Reminders done", zap.Int("sent", sent), zap.Int("failed", failed))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d reminders not sent", failed, len(reminders))
+	}
+	if err := s.repo.SaveReminderRun(ctx, today); err != nil {
+		s.log.Error(ctx, "Service.Reminders save run error", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"effective_mobile/internal/models"
+	"testing"
+	"time"
+)
+
+// reminderRepoStub запоминает окно, за которое запрошены напоминания.
+type reminderRepoStub struct {
+	lastRun  models.Date
+	from, to models.Date
+	saved    models.Date
+}
+
+func (r *reminderRepoStub) SelectReminders(_ context.Context, from, to models.Date) ([]models.Reminder, error) {
+	r.from, r.to = from, to
+	return nil, nil
+}
+
+func (r *reminderRepoStub) MarkReminderSent(context.Context, models.Reminder) error { return nil }
+
+func (r *reminderRepoStub) SelectReminderRun(context.Context) (models.Date, error) {
+	return r.lastRun, nil
+}
+
+func (r *reminderRepoStub) SaveReminderRun(_ context.Context, day models.Date) error {
+	r.saved = day
+	return nil
+}
+
+func TestReminderWindow(t *testing.T) {
+	today := models.DateOf(time.Now())
+	daysAgo := func(n int) models.Date { return models.DateOf(today.AddDate(0, 0, -n)) }
+
+	tests := []struct {
+		name     string
+		lastRun  models.Date
+		wantFrom models.Date
+	}{
+		{"first run", models.Date{}, today},
+		{"recent run", daysAgo(2), daysAgo(2)},
+		{"lookback caps an old run", daysAgo(30), daysAgo(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &reminderRepoStub{lastRun: tt.lastRun}
+			s := NewReminderService(repo, nil, 3, 7, "test")
+
+			if err := s.Run(context.Background()); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if !repo.from.Equal(tt.wantFrom.Time) {
+				t.Errorf("from = %s, want %s", repo.from, tt.wantFrom)
+			}
+			if want := models.DateOf(today.AddDate(0, 0, 3)); !repo.to.Equal(want.Time) {
+				t.Errorf("to = %s, want %s", repo.to, want)
+			}
+			if !repo.saved.Equal(today.Time) {
+				t.Errorf("saved run = %s, want %s", repo.saved, today)
+			}
+		})
+	}
+}
@@ -4,18 +4,44 @@ import (
 	"context"
 	"effective_mobile/internal/models"
 	"effective_mobile/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// ErrAmbiguousSubscription возвращается, когда пара (name, user_id) указывает на несколько подписок.
+var ErrAmbiguousSubscription = fmt.Errorf("%w: multiple subscriptions match name and user_id", models.ErrConflict)
+
+// ErrInvalidTransition возвращается, когда из текущего состояния подписки нельзя перейти в запрошенное.
+var ErrInvalidTransition = fmt.Errorf("%w: status transition is not allowed", models.ErrConflict)
+
 type SubscriptionRepository interface {
-	Select(ctx context.Context, limit, offset int) []models.Subscription
-	SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) (models.Subscription, error)
+	Select(ctx context.Context, filter models.SubscriptionFilter) ([]models.Subscription, error)
+	Count(ctx context.Context, filter models.SubscriptionFilter) (int, error)
+	SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) ([]models.Subscription, error)
+	SelectByID(ctx context.Context, id int, includeDeleted bool) (models.Subscription, error)
 	Insert(ctx context.Context, subscription *models.Subscription) error
-	Update(ctx context.Context, subscription models.Subscription) error
-	Delete(ctx context.Context, name string, id uuid.UUID) error
-	SumPrice(ctx context.Context, name string, id uuid.UUID, startDate string, endDate string) (int, error)
+	UpdateByID(ctx context.Context, subscription *models.Subscription) error
+	PatchByID(ctx context.Context, id, version int, changes map[string]any) (models.Subscription, error)
+	DeleteByID(ctx context.Context, id, version int) error
+	RestoreByID(ctx context.Context, id, version int) (models.Subscription, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	SelectHistory(ctx context.Context, id int) ([]models.AuditEntry, error)
+	SelectPrices(ctx context.Context, id int) ([]models.PricePeriod, error)
+	AddPrice(ctx context.Context, id, version int, p models.PricePeriod) (models.Subscription, error)
+	SelectStatuses(ctx context.Context, id int) ([]models.StatusPeriod, error)
+	SelectServiceByID(ctx context.Context, id int) (models.Service, error)
+	Transition(ctx context.Context, id, version int, p models.StatusPeriod) (models.Subscription, error)
+	SumPrice(ctx context.Context, q models.CostQuery) (int, error)
+	SumPriceDetails(ctx context.Context, q models.CostQuery) ([]models.SubscriptionCost, int, error)
+	SumPriceGroups(ctx context.Context, q models.CostQuery, groupBy string) ([]models.CostGroup, error)
+	SelectCharges(ctx context.Context, q models.ChargeQuery) ([]models.Charge, error)
+	Analytics(ctx context.Context, q models.CostQuery) (models.Analytics, error)
 }
 
 type SubscriptionService struct {
@@ -30,38 +56,182 @@ func NewSubscriptionService(repository SubscriptionRepository, env string) *Subs
 	}
 }
 
-func (s *SubscriptionService) Select(ctx context.Context, limit, offset int) []models.Subscription {
-	s.log.Debug(ctx, "Service.Select called",
-		zap.Int("limit", limit),
-		zap.Int("offset", offset),
-	)
+func (s *SubscriptionService) Select(ctx context.Context, filter models.SubscriptionFilter) ([]models.Subscription, error) {
+	s.log.Debug(ctx, "Service.Select called", zap.Any("filter", filter))
+
+	subs, err := s.repo.Select(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, "Service.Select error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Select result",
+			zap.Int("subscriptions_count", len(subs)),
+		)
+	}
+	return subs, err
+}
+
+// SelectPage возвращает страницу подписок и курсор следующей страницы, если она есть.
+// Чтобы понять, есть ли продолжение, из репозитория запрашивается на одну строку больше.
+func (s *SubscriptionService) SelectPage(ctx context.Context, filter models.SubscriptionFilter, withTotal bool) (models.SubscriptionPage, error) {
+	s.log.Debug(ctx, "Service.SelectPage called", zap.Any("filter", filter), zap.Bool("with_total", withTotal))
+
+	limit := filter.Limit
+	filter.Limit++
+
+	subs, err := s.repo.Select(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectPage error", zap.Error(err))
+		return models.SubscriptionPage{}, err
+	}
+
+	page := models.SubscriptionPage{Items: subs}
+	if len(subs) > limit {
+		page.Items = subs[:limit]
+		page.NextCursor = models.CursorAfter(page.Items[limit-1], filter.Sort).Encode()
+	}
 
-	subs := s.repo.Select(ctx, limit, offset)
+	if withTotal {
+		total, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			s.log.Error(ctx, "Service.SelectPage count error", zap.Error(err))
+			return models.SubscriptionPage{}, err
+		}
+		page.Total = &total
+	}
 
-	s.log.Debug(ctx, "Service.Select result",
-		zap.Int("subscriptions_count", len(subs)),
+	s.log.Debug(ctx, "Service.SelectPage result",
+		zap.Int("subscriptions_count", len(page.Items)),
+		zap.Bool("has_next", page.NextCursor != ""),
 	)
-	return subs
+	return page, nil
 }
 
-func (s *SubscriptionService) SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) (models.Subscription, error) {
+func (s *SubscriptionService) SelectByNameAndUserID(ctx context.Context, name string, id uuid.UUID) ([]models.Subscription, error) {
 	s.log.Debug(ctx, "Service.SelectByNameAndUserID called",
 		zap.String("name", name),
 		zap.String("user_id", id.String()),
 	)
 
-	sub, err := s.repo.SelectByNameAndUserID(ctx, name, id)
+	subs, err := s.repo.SelectByNameAndUserID(ctx, name, id)
 	if err != nil {
 		s.log.Error(ctx, "Service.SelectByNameAndUserID error", zap.Error(err))
 	} else {
-		s.log.Debug(ctx, "Service.SelectByNameAndUserID result", zap.Any("subscription", sub))
+		s.log.Debug(ctx, "Service.SelectByNameAndUserID result", zap.Int("subscriptions_count", len(subs)))
+	}
+
+	return subs, err
+}
+
+// ResolveByNameAndUserID находит единственную подписку по имени и пользователю.
+// Если совпадений нет, возвращается models.ErrNotFound, если их несколько — ErrAmbiguousSubscription.
+func (s *SubscriptionService) ResolveByNameAndUserID(ctx context.Context, name string, id uuid.UUID) (models.Subscription, error) {
+	subs, err := s.SelectByNameAndUserID(ctx, name, id)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	switch len(subs) {
+	case 0:
+		return models.Subscription{}, fmt.Errorf("%w: no subscription %q for user %s", models.ErrNotFound, name, id)
+	case 1:
+		return subs[0], nil
+	default:
+		s.log.Info(ctx, "Service.ResolveByNameAndUserID: ambiguous match",
+			zap.String("name", name),
+			zap.String("user_id", id.String()),
+			zap.Int("subscriptions_count", len(subs)),
+		)
+		return models.Subscription{}, ErrAmbiguousSubscription
+	}
+}
+
+// SelectByID возвращает подписку по идентификатору; мягко удалённая подписка
+// находится только при includeDeleted.
+func (s *SubscriptionService) SelectByID(ctx context.Context, id int, includeDeleted bool) (models.Subscription, error) {
+	s.log.Debug(ctx, "Service.SelectByID called", zap.Int("id", id), zap.Bool("include_deleted", includeDeleted))
+
+	sub, err := s.repo.SelectByID(ctx, id, includeDeleted)
+	if err != nil {
+		s.log.Error(ctx, "Service.SelectByID error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SelectByID result", zap.Any("subscription", sub))
 	}
 
 	return sub, err
 }
 
+// normalizeSubscription подставляет значения по умолчанию перед проверкой и записью.
+func normalizeSubscription(subscription *models.Subscription) {
+	subscription.Name = strings.TrimSpace(subscription.Name)
+	subscription.Currency = models.NormalizeCurrency(subscription.Currency)
+	subscription.NormalizeBilling()
+	if subscription.Status == "" {
+		subscription.Status = models.StatusActive
+	}
+	subscription.Tags = normalizeTags(subscription.Tags)
+	sort.Slice(subscription.Members, func(i, j int) bool {
+		return subscription.Members[i].UserID.String() < subscription.Members[j].UserID.String()
+	})
+}
+
+// normalizeTags приводит теги к нижнему регистру, убирает пробелы по краям и повторы
+// и сортирует их; пустые теги остаются, чтобы их отклонила проверка. nil (теги не переданы)
+// остаётся nil, чтобы отличать его от пустого списка.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// nameFromService подставляет название сервиса service_id, если название подписки не задано.
+func (s *SubscriptionService) nameFromService(ctx context.Context, subscription *models.Subscription) error {
+	if subscription.Name != "" || subscription.ServiceID == 0 {
+		return nil
+	}
+
+	service, err := s.repo.SelectServiceByID(ctx, subscription.ServiceID)
+	if errors.Is(err, models.ErrNotFound) {
+		return &models.ValidationError{Violations: []models.FieldViolation{
+			{Field: "service_id", Message: "unknown service"},
+		}}
+	}
+	if err != nil {
+		s.log.Error(ctx, "Service.nameFromService error", zap.Error(err))
+		return err
+	}
+
+	subscription.Name = service.Name
+	return nil
+}
+
+// Validate проверяет подписку по тем же правилам, что Insert и UpdateByID, ничего не записывая.
+func (s *SubscriptionService) Validate(subscription models.Subscription) error {
+	normalizeSubscription(&subscription)
+	return validateSubscription(subscription)
+}
+
 func (s *SubscriptionService) Insert(ctx context.Context, subscription *models.Subscription) error {
 	s.log.Debug(ctx, "Service.Insert called", zap.Any("subscription", subscription))
+	normalizeSubscription(subscription)
+	if err := s.nameFromService(ctx, subscription); err != nil {
+		return err
+	}
+	if err := validateSubscription(*subscription, initialStatusRule); err != nil {
+		s.log.Debug(ctx, "Service.Insert validation failed", zap.Error(err))
+		return err
+	}
+
 	err := s.repo.Insert(ctx, subscription)
 	if err != nil {
 		s.log.Error(ctx, "Service.Insert error", zap.Error(err))
@@ -71,41 +241,284 @@ func (s *SubscriptionService) Insert(ctx context.Context, subscription *models.S
 	return err
 }
 
-func (s *SubscriptionService) Update(ctx context.Context, subscription models.Subscription) error {
-	s.log.Debug(ctx, "Service.Update called", zap.Any("subscription", subscription))
-	err := s.repo.Update(ctx, subscription)
+// UpdateByID заменяет все поля подписки, кроме состояния: оно меняется только через Transition.
+// Если subscription.Version больше нуля, подписка должна быть в этой версии; после записи
+// в subscription попадает сохранённая подписка с новой версией.
+func (s *SubscriptionService) UpdateByID(ctx context.Context, subscription *models.Subscription) error {
+	s.log.Debug(ctx, "Service.UpdateByID called", zap.Any("subscription", subscription))
+	normalizeSubscription(subscription)
+	if err := s.nameFromService(ctx, subscription); err != nil {
+		return err
+	}
+	if err := validateSubscription(*subscription); err != nil {
+		s.log.Debug(ctx, "Service.UpdateByID validation failed", zap.Error(err))
+		return err
+	}
+
+	err := s.repo.UpdateByID(ctx, subscription)
 	if err != nil {
-		s.log.Error(ctx, "Service.Update error", zap.Error(err))
+		s.log.Error(ctx, "Service.UpdateByID error", zap.Error(err))
 	} else {
-		s.log.Debug(ctx, "Service.Update successful")
+		s.log.Debug(ctx, "Service.UpdateByID successful")
 	}
 	return err
 }
 
-func (s *SubscriptionService) Delete(ctx context.Context, name string, id uuid.UUID) error {
-	s.log.Debug(ctx, "Service.Delete called",
-		zap.String("name", name),
-		zap.String("user_id", id.String()),
+// Patch применяет JSON Merge Patch к подписке id. Проверяется подписка целиком после изменения,
+// а в базу записываются только затронутые патчем колонки. Если version больше нуля,
+// подписка должна быть в этой версии, иначе возвращается models.ErrStaleVersion.
+func (s *SubscriptionService) Patch(ctx context.Context, id, version int, patch models.SubscriptionPatch) (models.Subscription, error) {
+	s.log.Debug(ctx, "Service.Patch called",
+		zap.Int("id", id),
+		zap.Int("version", version),
+		zap.Strings("fields", patch.Fields()),
 	)
 
-	err := s.repo.Delete(ctx, name, id)
+	sub, err := s.repo.SelectByID(ctx, id, false)
+	if err != nil {
+		s.log.Error(ctx, "Service.Patch select error", zap.Error(err))
+		return models.Subscription{}, err
+	}
+	if version > 0 && sub.Version != version {
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d is at version %d", models.ErrStaleVersion, id, sub.Version)
+	}
+
+	fields := patch.Fields()
+	if len(fields) == 0 {
+		return sub, nil
+	}
+
+	patch.Apply(&sub)
+	normalizeSubscription(&sub)
+	if err := validateSubscription(sub); err != nil {
+		s.log.Debug(ctx, "Service.Patch validation failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	// Версия прочитанной строки защищает от изменений между чтением и записью
+	updated, err := s.repo.PatchByID(ctx, id, sub.Version, patchChanges(sub, fields))
+	if err != nil {
+		s.log.Error(ctx, "Service.Patch error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Patch successful", zap.Int("subscription_id", updated.ID))
+	}
+	return updated, err
+}
+
+// patchChanges собирает значения изменённых колонок из уже нормализованной подписки.
+// Смена периода оплаты может сбросить interval_months, поэтому он записывается вместе с ним.
+// Сервис по новому названию находит репозиторий в транзакции записи.
+func patchChanges(sub models.Subscription, fields []string) map[string]any {
+	changes := make(map[string]any, len(fields)+1)
+	for _, f := range fields {
+		switch f {
+		case "name":
+			changes[f] = sub.Name
+		case "price":
+			changes[f] = sub.Price
+		case "currency":
+			changes[f] = sub.Currency
+		case "user_id":
+			changes[f] = sub.UserID
+		case "start_date":
+			changes[f] = sub.StartDate
+		case "end_date":
+			changes[f] = sub.EndDate
+		case "billing_period":
+			changes[f] = sub.BillingPeriod
+			changes["interval_months"] = sub.IntervalMonths
+		case "interval_months":
+			changes[f] = sub.IntervalMonths
+		case "anchor_day":
+			changes[f] = sub.AnchorDay
+		case "tags":
+			changes[f] = sub.Tags
+		case "members":
+			changes[f] = sub.Members
+		}
+	}
+	return changes
+}
+
+// DeleteByID мягко удаляет подписку; если version больше нуля, то только в этой версии.
+func (s *SubscriptionService) DeleteByID(ctx context.Context, id, version int) error {
+	s.log.Debug(ctx, "Service.DeleteByID called", zap.Int("id", id), zap.Int("version", version))
+
+	err := s.repo.DeleteByID(ctx, id, version)
 	if err != nil {
-		s.log.Error(ctx, "Service.Delete error", zap.Error(err))
+		s.log.Error(ctx, "Service.DeleteByID error", zap.Error(err))
 	} else {
-		s.log.Debug(ctx, "Service.Delete successful")
+		s.log.Debug(ctx, "Service.DeleteByID successful")
 	}
 	return err
 }
 
-func (s *SubscriptionService) SumPrice(ctx context.Context, name string, id uuid.UUID, startDate string, endDate string) (int, error) {
-	s.log.Debug(ctx, "Service.SumPrice called",
-		zap.String("name", name),
-		zap.String("user_id", id.String()),
-		zap.String("start_date", startDate),
-		zap.String("end_date", endDate),
-	)
+// RestoreByID отменяет мягкое удаление подписки; если version больше нуля, то только в этой версии.
+func (s *SubscriptionService) RestoreByID(ctx context.Context, id, version int) (models.Subscription, error) {
+	s.log.Debug(ctx, "Service.RestoreByID called", zap.Int("id", id), zap.Int("version", version))
+
+	sub, err := s.repo.RestoreByID(ctx, id, version)
+	if err != nil {
+		s.log.Error(ctx, "Service.RestoreByID error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.RestoreByID successful", zap.Int("subscription_id", sub.ID))
+	}
+	return sub, err
+}
+
+// PurgeDeleted окончательно удаляет подписки, мягко удалённые больше retention назад.
+func (s *SubscriptionService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+	s.log.Debug(ctx, "Service.PurgeDeleted called", zap.Time("before", before))
+
+	purged, err := s.repo.PurgeDeleted(ctx, before)
+	if err != nil {
+		s.log.Error(ctx, "Service.PurgeDeleted error", zap.Error(err))
+	} else if purged > 0 {
+		s.log.Info(ctx, "Service.PurgeDeleted: subscriptions purged", zap.Int64("purged_count", purged))
+	}
+	return purged, err
+}
 
-	sum, err := s.repo.SumPrice(ctx, name, id, startDate, endDate)
+// History возвращает журнал изменений подписки. Журнал окончательно удалённой подписки
+// остаётся доступен; у подписок, созданных до появления журнала, он может быть пустым.
+// models.ErrNotFound возвращается, только если нет ни записей, ни самой подписки.
+func (s *SubscriptionService) History(ctx context.Context, id int) ([]models.AuditEntry, error) {
+	s.log.Debug(ctx, "Service.History called", zap.Int("id", id))
+
+	entries, err := s.repo.SelectHistory(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.History error", zap.Error(err))
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if _, err := s.repo.SelectByID(ctx, id, true); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Debug(ctx, "Service.History result", zap.Int("entries_count", len(entries)))
+	return entries, nil
+}
+
+// Prices возвращает периоды цены подписки, в том числе мягко удалённой.
+func (s *SubscriptionService) Prices(ctx context.Context, id int) ([]models.PricePeriod, error) {
+	s.log.Debug(ctx, "Service.Prices called", zap.Int("id", id))
+
+	if _, err := s.repo.SelectByID(ctx, id, true); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.SelectPrices(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.Prices error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Prices result", zap.Int("prices_count", len(prices)))
+	}
+	return prices, err
+}
+
+// AddPrice меняет цену подписки начиная с месяца p.EffectiveFrom, не затрагивая более ранние месяцы.
+// Задним числом цену менять можно, заранее — только для подписки, которая ещё не началась,
+// и не позже её начала. Если version больше нуля, подписка должна быть в этой версии.
+func (s *SubscriptionService) AddPrice(ctx context.Context, id, version int, p models.PricePeriod) (models.Subscription, error) {
+	s.log.Debug(ctx, "Service.AddPrice called", zap.Int("id", id), zap.Int("version", version), zap.Any("price", p))
+
+	sub, err := s.repo.SelectByID(ctx, id, false)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	if err := validatePrice(p, sub.StartDate); err != nil {
+		s.log.Debug(ctx, "Service.AddPrice validation failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	updated, err := s.repo.AddPrice(ctx, id, version, p)
+	if err != nil {
+		s.log.Error(ctx, "Service.AddPrice error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.AddPrice successful", zap.Int("price", updated.Price))
+	}
+	return updated, err
+}
+
+// Statuses возвращает периоды состояния подписки, в том числе мягко удалённой.
+func (s *SubscriptionService) Statuses(ctx context.Context, id int) ([]models.StatusPeriod, error) {
+	s.log.Debug(ctx, "Service.Statuses called", zap.Int("id", id))
+
+	if _, err := s.repo.SelectByID(ctx, id, true); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.repo.SelectStatuses(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.Statuses error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Statuses result", zap.Int("statuses_count", len(statuses)))
+	}
+	return statuses, err
+}
+
+// Transition переводит подписку в состояние p.Status начиная с месяца p.EffectiveFrom, по умолчанию —
+// с текущего месяца (для ещё не начавшейся подписки — с её начала). Допустимые переходы задаёт
+// models.CanTransition, для остальных возвращается ErrInvalidTransition. Если version больше нуля,
+// подписка должна быть в этой версии, иначе возвращается models.ErrStaleVersion.
+func (s *SubscriptionService) Transition(ctx context.Context, id, version int, p models.StatusPeriod) (models.Subscription, error) {
+	s.log.Debug(ctx, "Service.Transition called", zap.Int("id", id), zap.Int("version", version), zap.Any("status", p))
+
+	sub, err := s.repo.SelectByID(ctx, id, false)
+	if err != nil {
+		s.log.Error(ctx, "Service.Transition select error", zap.Error(err))
+		return models.Subscription{}, err
+	}
+	if version > 0 && sub.Version != version {
+		return models.Subscription{}, fmt.Errorf("%w: subscription %d is at version %d", models.ErrStaleVersion, id, sub.Version)
+	}
+	if !models.CanTransition(sub.Status, p.Status) {
+		return models.Subscription{}, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, sub.Status, p.Status)
+	}
+
+	statuses, err := s.repo.SelectStatuses(ctx, id)
+	if err != nil {
+		s.log.Error(ctx, "Service.Transition select statuses error", zap.Error(err))
+		return models.Subscription{}, err
+	}
+	if p.EffectiveFrom.IsZero() {
+		p.EffectiveFrom = models.ChangeMonth(sub.StartDate)
+	}
+	if err := validateStatusChange(p, sub.StartDate, statuses); err != nil {
+		s.log.Debug(ctx, "Service.Transition validation failed", zap.Error(err))
+		return models.Subscription{}, err
+	}
+
+	// Версия прочитанной строки защищает от параллельного перехода между проверкой и записью
+	updated, err := s.repo.Transition(ctx, id, sub.Version, p)
+	if err != nil {
+		s.log.Error(ctx, "Service.Transition error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Transition successful", zap.String("status", updated.Status))
+	}
+	return updated, err
+}
+
+// normalizeCostQuery подставляет значения по умолчанию: текущий месяц вместо пустого
+// конца периода и базовую валюту вместо пустой.
+func normalizeCostQuery(q models.CostQuery) models.CostQuery {
+	if q.EndDate.IsZero() {
+		q.EndDate = models.YearMonthOf(time.Now())
+	}
+	q.Currency = models.NormalizeCurrency(q.Currency)
+	return q
+}
+
+// SumPrice считает стоимость подписок за период: каждое списание, которое по периоду оплаты
+// подписки приходится на [StartDate, EndDate], пересчитывается в валюту запроса по курсу,
+// действовавшему на дату списания.
+func (s *SubscriptionService) SumPrice(ctx context.Context, q models.CostQuery) (int, error) {
+	q = normalizeCostQuery(q)
+	s.log.Debug(ctx, "Service.SumPrice called", zap.Any("query", q))
+
+	sum, err := s.repo.SumPrice(ctx, q)
 	if err != nil {
 		s.log.Error(ctx, "Service.SumPrice error", zap.Error(err))
 	} else {
@@ -114,3 +527,77 @@ func (s *SubscriptionService) SumPrice(ctx context.Context, name string, id uuid
 
 	return sum, err
 }
+
+// SumPriceDetails возвращает стоимость каждой подписки за период по тем же правилам, что и SumPrice,
+// и общую сумму, посчитанную тем же запросом и совпадающую с SumPrice.
+func (s *SubscriptionService) SumPriceDetails(ctx context.Context, q models.CostQuery) ([]models.SubscriptionCost, int, error) {
+	q = normalizeCostQuery(q)
+	s.log.Debug(ctx, "Service.SumPriceDetails called", zap.Any("query", q))
+
+	costs, total, err := s.repo.SumPriceDetails(ctx, q)
+	if err != nil {
+		s.log.Error(ctx, "Service.SumPriceDetails error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SumPriceDetails result",
+			zap.Int("subscriptions_count", len(costs)), zap.Int("sum", total))
+	}
+
+	return costs, total, err
+}
+
+// SumPriceGroups возвращает стоимость подписок за период в разрезе категорий сервисов
+// (models.GroupByCategory) или тегов (models.GroupByTag) по тем же правилам, что и SumPrice.
+func (s *SubscriptionService) SumPriceGroups(ctx context.Context, q models.CostQuery, groupBy string) ([]models.CostGroup, error) {
+	q = normalizeCostQuery(q)
+	s.log.Debug(ctx, "Service.SumPriceGroups called", zap.Any("query", q), zap.String("group_by", groupBy))
+
+	groups, err := s.repo.SumPriceGroups(ctx, q, groupBy)
+	if err != nil {
+		s.log.Error(ctx, "Service.SumPriceGroups error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.SumPriceGroups result", zap.Int("groups_count", len(groups)))
+	}
+
+	return groups, err
+}
+
+// Analytics возвращает стоимость подписок за период в разрезе месяцев, сервисов и пользователей
+// по тем же правилам, что и SumPrice.
+func (s *SubscriptionService) Analytics(ctx context.Context, q models.CostQuery) (models.Analytics, error) {
+	q = normalizeCostQuery(q)
+	s.log.Debug(ctx, "Service.Analytics called", zap.Any("query", q))
+
+	a, err := s.repo.Analytics(ctx, q)
+	if err != nil {
+		s.log.Error(ctx, "Service.Analytics error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Analytics result", zap.Int("total", a.Total))
+	}
+
+	return a, err
+}
+
+// Charges разворачивает подписки в отдельные списания за [From, To] по их периодам оплаты.
+// Пустой From означает сегодня, пустой To — месяц после From.
+func (s *SubscriptionService) Charges(ctx context.Context, q models.ChargeQuery) ([]models.Charge, error) {
+	if q.From.IsZero() {
+		q.From = models.DateOf(time.Now())
+	}
+	if q.To.IsZero() {
+		q.To = models.Date{Time: q.From.AddDate(0, 1, -1)}
+	}
+	if q.Currency != "" {
+		q.Currency = models.NormalizeCurrency(q.Currency)
+	}
+
+	s.log.Debug(ctx, "Service.Charges called", zap.Any("query", q))
+
+	charges, err := s.repo.SelectCharges(ctx, q)
+	if err != nil {
+		s.log.Error(ctx, "Service.Charges error", zap.Error(err))
+	} else {
+		s.log.Debug(ctx, "Service.Charges result", zap.Int("charges_count", len(charges)))
+	}
+
+	return charges, err
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"effective_mobile/internal/models"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxNameLength ограничивает длину названия сервиса в символах.
+const maxNameLength = 100
+
+// Ограничения на теги и участников подписки.
+const (
+	maxTags      = 20
+	maxTagLength = 50
+	maxMembers   = 10
+)
+
+// fieldRule — правило проверки одного поля: пустая строка означает, что поле корректно.
+type fieldRule struct {
+	field string
+	check func(s models.Subscription) string
+}
+
+// subscriptionRules — все правила для подписки. Проверяются после NormalizeBilling
+// и нормализации валюты, поэтому значения по умолчанию уже подставлены.
+var subscriptionRules = []fieldRule{
+	{"name", func(s models.Subscription) string {
+		return checkServiceName(strings.TrimSpace(s.Name))
+	}},
+	{"price", func(s models.Subscription) string {
+		return checkPrice(s.Price)
+	}},
+	{"currency", func(s models.Subscription) string {
+		if !models.IsSupportedCurrency(s.Currency) {
+			return fmt.Sprintf("unsupported currency %q", s.Currency)
+		}
+		return ""
+	}},
+	{"user_id", func(s models.Subscription) string {
+		if s.UserID == uuid.Nil {
+			return "is required"
+		}
+		return ""
+	}},
+	{"start_date", func(s models.Subscription) string {
+		if s.StartDate.IsZero() {
+			return "is required"
+		}
+		return ""
+	}},
+	{"end_date", func(s models.Subscription) string {
+		if s.EndDate != nil && !s.StartDate.IsZero() && s.EndDate.Before(s.StartDate) {
+			return "must not be before start_date"
+		}
+		return ""
+	}},
+	{"billing_period", func(s models.Subscription) string {
+		switch s.BillingPeriod {
+		case models.BillingWeekly, models.BillingMonthly, models.BillingQuarterly,
+			models.BillingYearly, models.BillingCustom:
+			return ""
+		}
+		return fmt.Sprintf("unknown billing_period %q", s.BillingPeriod)
+	}},
+	{"interval_months", func(s models.Subscription) string {
+		if s.BillingPeriod == models.BillingCustom && (s.IntervalMonths == nil || *s.IntervalMonths < 1) {
+			return "must be positive for custom billing"
+		}
+		return ""
+	}},
+	{"tags", func(s models.Subscription) string {
+		if len(s.Tags) > maxTags {
+			return fmt.Sprintf("must have at most %d tags", maxTags)
+		}
+		for _, tag := range s.Tags {
+			switch {
+			case tag == "":
+				return "must not contain empty tags"
+			case utf8.RuneCountInString(tag) > maxTagLength:
+				return fmt.Sprintf("tag %q must be at most %d characters", tag, maxTagLength)
+			}
+		}
+		return ""
+	}},
+	{"members", func(s models.Subscription) string {
+		if len(s.Members) > maxMembers {
+			return fmt.Sprintf("must have at most %d members", maxMembers)
+		}
+		seen := make(map[uuid.UUID]bool, len(s.Members))
+		for _, m := range s.Members {
+			switch {
+			case m.UserID == uuid.Nil:
+				return "user_id of every member is required"
+			case seen[m.UserID]:
+				return fmt.Sprintf("member %s is listed twice", m.UserID)
+			case m.Share < 1:
+				return fmt.Sprintf("share of member %s must be positive", m.UserID)
+			}
+			seen[m.UserID] = true
+		}
+		return ""
+	}},
+	{"status", func(s models.Subscription) string {
+		if !models.IsKnownStatus(s.Status) {
+			return fmt.Sprintf("unknown status %q", s.Status)
+		}
+		return ""
+	}},
+	// Для еженедельной оплаты anchor_day — день недели по ISO (1 — понедельник), для остальных —
+	// день месяца; если в месяце столько дней нет, списание происходит в последний день месяца.
+	{"anchor_day", func(s models.Subscription) string {
+		if s.BillingPeriod == models.BillingWeekly {
+			if s.AnchorDay < 1 || s.AnchorDay > 7 {
+				return "must be between 1 and 7 for weekly billing"
+			}
+			return ""
+		}
+		if s.AnchorDay < 1 || s.AnchorDay > 31 {
+			return "must be between 1 and 31"
+		}
+		return ""
+	}},
+}
+
+// initialStatusRule — новая подписка начинается с пробного периода или сразу активной,
+// остальные состояния достижимы только переходами.
+var initialStatusRule = fieldRule{"status", func(s models.Subscription) string {
+	if s.Status != models.StatusTrial && s.Status != models.StatusActive {
+		return "must be trial or active for a new subscription"
+	}
+	return ""
+}}
+
+func checkPrice(price int) string {
+	switch {
+	case price < 0:
+		return "must not be negative"
+	case price > math.MaxInt32:
+		return fmt.Sprintf("must not exceed %d", math.MaxInt32)
+	}
+	return ""
+}
+
+// validatePrice проверяет новый период цены подписки, начинающейся в start: цена допустима,
+// а период начинается не позже текущего месяца или, для ещё не начавшейся подписки, её начала.
+func validatePrice(p models.PricePeriod, start models.YearMonth) error {
+	var violations []models.FieldViolation
+	if msg := checkPrice(p.Price); msg != "" {
+		violations = append(violations, models.FieldViolation{Field: "price", Message: msg})
+	}
+
+	latest := models.ChangeMonth(start)
+	switch {
+	case p.EffectiveFrom.IsZero():
+		violations = append(violations, models.FieldViolation{Field: "effective_from", Message: "is required"})
+	case latest.Before(p.EffectiveFrom):
+		violations = append(violations, models.FieldViolation{
+			Field:   "effective_from",
+			Message: fmt.Sprintf("must not be after %s", latest),
+		})
+	}
+
+	if len(violations) > 0 {
+		return &models.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateStatusChange проверяет месяц, с которого подписка, начинающаяся в start, переходит
+// в новое состояние: не раньше её начала и последнего из периодов состояния current
+// (переход не переписывает уже начавшиеся периоды) и не позже models.ChangeMonth.
+func validateStatusChange(p models.StatusPeriod, start models.YearMonth, current []models.StatusPeriod) error {
+	earliest := start
+	if n := len(current); n > 0 && earliest.Before(current[n-1].EffectiveFrom) {
+		earliest = current[n-1].EffectiveFrom
+	}
+	latest := models.ChangeMonth(start)
+
+	var msg string
+	switch {
+	case p.EffectiveFrom.Before(earliest):
+		msg = fmt.Sprintf("must not be before %s", earliest)
+	case latest.Before(p.EffectiveFrom):
+		msg = fmt.Sprintf("must not be after %s", latest)
+	default:
+		return nil
+	}
+	return &models.ValidationError{Violations: []models.FieldViolation{{Field: "effective_from", Message: msg}}}
+}
+
+// validateSubscription проверяет все поля подписки по subscriptionRules и дополнительным
+// правилам extra и возвращает *models.ValidationError со всеми найденными нарушениями сразу.
+func validateSubscription(s models.Subscription, extra ...fieldRule) error {
+	var violations []models.FieldViolation
+	rules := append(append([]fieldRule{}, subscriptionRules...), extra...)
+	for _, rule := range rules {
+		if msg := rule.check(s); msg != "" {
+			violations = append(violations, models.FieldViolation{Field: rule.field, Message: msg})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &models.ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"effective_mobile/internal/handlers"
 	"effective_mobile/internal/service"
 	middleware "effective_mobile/internal/transport"
+	"effective_mobile/pkg/problem"
 
 	_ "effective_mobile/internal/docs"
 
@@ -19,77 +20,198 @@ var (
 	defaultHeaderTimeout = time.Second * 5
 )
 
+const (
+	subscriptionsPath = "/api/v1/subscriptions"
+	usersPath         = "/api/v1/users"
+	servicesPath      = "/api/v1/services"
+	adminPath         = "/api/v1/admin"
+)
+
 type Server struct {
-	srv  *http.Server
-	Subs *handlers.SubscriptionHandler
+	srv     *http.Server
+	Subs    *handlers.SubscriptionHandler
+	Rates   *handlers.CurrencyHandler
+	Catalog *handlers.CatalogHandler
+	Budgets *handlers.BudgetHandler
 }
 
-func NewServer(port int, subsService *service.SubscriptionService) *Server {
+func NewServer(
+	port int, requireIfMatch bool,
+	subsService *service.SubscriptionService, currencyService *service.CurrencyService, catalogService *service.CatalogService,
+	budgetService *service.BudgetService,
+) *Server {
 	srv := http.Server{
 		Addr:              ":" + strconv.Itoa(port),
 		Handler:           nil,
 		ReadHeaderTimeout: defaultHeaderTimeout,
 	}
 	return &Server{
-		srv:  &srv,
-		Subs: &handlers.SubscriptionHandler{Service: subsService},
+		srv:     &srv,
+		Subs:    &handlers.SubscriptionHandler{Service: subsService, RequireIfMatch: requireIfMatch},
+		Rates:   &handlers.CurrencyHandler{Service: currencyService},
+		Catalog: &handlers.CatalogHandler{Service: catalogService},
+		Budgets: &handlers.BudgetHandler{Service: budgetService},
 	}
 }
 
 func (s *Server) RegisterHandlers() {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/api/v1/subscriptions/create", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+	mux.HandleFunc("GET "+subscriptionsPath, s.Subs.List)
+	mux.HandleFunc("POST "+subscriptionsPath, func(w http.ResponseWriter, r *http.Request) {
 		s.Subs.Create(r.Context(), w, r)
 	})
 
-	mux.HandleFunc("/api/v1/subscriptions/list", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		s.Subs.List(w, r)
+	mux.HandleFunc("GET "+subscriptionsPath+"/sum", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.SumPrice(r.Context(), w, r)
 	})
 
-	mux.HandleFunc("/api/v1/subscriptions/get", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		s.Subs.Get(r.Context(), w, r)
+	mux.HandleFunc("GET "+subscriptionsPath+"/analytics", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Analytics(r.Context(), w, r)
 	})
 
-	mux.HandleFunc("/api/v1/subscriptions/update", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPut {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		s.Subs.Update(r.Context(), w, r)
+	mux.HandleFunc("GET "+subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.GetByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("PUT "+subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.UpdateByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("PATCH "+subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Patch(r.Context(), w, r)
+	})
+	mux.HandleFunc("DELETE "+subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.DeleteByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+subscriptionsPath+"/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Restore(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+subscriptionsPath+"/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.History(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+subscriptionsPath+"/{id}/prices", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Prices(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+subscriptionsPath+"/{id}/prices", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.AddPrice(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+subscriptionsPath+"/{id}/statuses", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Statuses(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+subscriptionsPath+"/{id}/activate", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Activate(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+subscriptionsPath+"/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Pause(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+subscriptionsPath+"/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Cancel(r.Context(), w, r)
 	})
 
-	mux.HandleFunc("/api/v1/subscriptions/delete", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodDelete {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		s.Subs.Delete(r.Context(), w, r)
+	mux.HandleFunc("GET "+usersPath+"/{user_id}/charges", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Charges(r.Context(), w, r)
+	})
+
+	mux.HandleFunc("GET "+usersPath+"/{user_id}/budgets", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.List(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+usersPath+"/{user_id}/budgets", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.Create(r.Context(), w, r)
 	})
+	mux.HandleFunc("GET "+usersPath+"/{user_id}/budgets/status", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.Status(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+usersPath+"/{user_id}/budgets/events", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.Events(r.Context(), w, r)
+	})
+	mux.HandleFunc("PUT "+usersPath+"/{user_id}/budgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.UpdateByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("DELETE "+usersPath+"/{user_id}/budgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.DeleteByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+servicesPath, func(w http.ResponseWriter, r *http.Request) {
+		s.Catalog.List(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+servicesPath, func(w http.ResponseWriter, r *http.Request) {
+		s.Catalog.Create(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+servicesPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Catalog.GetByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("PUT "+servicesPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Catalog.UpdateByID(r.Context(), w, r)
+	})
+	mux.HandleFunc("DELETE "+servicesPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Catalog.DeleteByID(r.Context(), w, r)
+	})
+
+	mux.HandleFunc("GET "+adminPath+"/currency-rates", func(w http.ResponseWriter, r *http.Request) {
+		s.Rates.ListRates(r.Context(), w, r)
+	})
+	mux.HandleFunc("POST "+adminPath+"/currency-rates", func(w http.ResponseWriter, r *http.Request) {
+		s.Rates.LoadRates(r.Context(), w, r)
+	})
+	mux.HandleFunc("GET "+adminPath+"/budget-events", func(w http.ResponseWriter, r *http.Request) {
+		s.Budgets.AllEvents(r.Context(), w, r)
+	})
+
+	// Устаревшие маршруты, оставлены для обратной совместимости
+	mux.HandleFunc("POST "+subscriptionsPath+"/create", deprecated(subscriptionsPath, func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Create(r.Context(), w, r)
+	}))
+	mux.HandleFunc("GET "+subscriptionsPath+"/list", deprecated(subscriptionsPath, s.Subs.List))
+	mux.HandleFunc("GET "+subscriptionsPath+"/get", deprecated(subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Get(r.Context(), w, r)
+	}))
+	mux.HandleFunc("PUT "+subscriptionsPath+"/update", deprecated(subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Update(r.Context(), w, r)
+	}))
+	mux.HandleFunc("DELETE "+subscriptionsPath+"/delete", deprecated(subscriptionsPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.Subs.Delete(r.Context(), w, r)
+	}))
+
+	mux.Handle("/swagger/", httpSwagger.WrapHandler)
+
+	s.srv.Handler = middleware.LoggingMiddleware(problemFallback(mux))
+}
 
-	mux.HandleFunc("/api/v1/subscriptions/sum", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+// problemFallback отвечает problem+json вместо текстовых 404 и 405, которые ServeMux
+// формирует для неизвестных путей и неразрешённых методов.
+func problemFallback(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
 			return
 		}
-		s.Subs.SumPrice(r.Context(), w, r)
+
+		// Ответ ServeMux нужен только ради статуса и заголовка Allow
+		rec := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		if allow := rec.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+		problem.Error(w, r, rec.status, http.StatusText(rec.status))
 	})
+}
 
-	mux.Handle("/swagger/", httpSwagger.WrapHandler)
+// statusRecorder запоминает заголовки и статус ответа и отбрасывает тело.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (rec *statusRecorder) Header() http.Header         { return rec.header }
+func (rec *statusRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (rec *statusRecorder) WriteHeader(status int)      { rec.status = status }
 
-	s.srv.Handler = middleware.LoggingMiddleware(mux)
+// deprecated помечает ответ заголовками Deprecation и Link на маршрут-преемник.
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
 }
 
 func (s *Server) Start() error {
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"effective_mobile/pkg/problem"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnmatchedRoutesAnswerWithProblem(t *testing.T) {
+	s := NewServer(0, true, nil, nil, nil, nil)
+	s.RegisterHandlers()
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		status    int
+		wantAllow bool
+	}{
+		{"unknown path", http.MethodGet, "/api/v1/unknown", http.StatusNotFound, false},
+		{"wrong method", http.MethodDelete, adminPath + "/currency-rates", http.StatusMethodNotAllowed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Fatalf("Content-Type = %q, want %q", ct, problem.ContentType)
+			}
+			if allow := w.Header().Get("Allow"); (allow != "") != tt.wantAllow {
+				t.Fatalf("Allow = %q, want present %v", allow, tt.wantAllow)
+			}
+
+			var p problem.Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if p.Status != tt.status || p.Instance != tt.path {
+				t.Fatalf("problem = %+v, want status %d for %s", p, tt.status, tt.path)
+			}
+		})
+	}
+}
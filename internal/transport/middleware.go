@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"context"
+	"effective_mobile/pkg/logger"
 	"log"
 	"net/http"
 	"time"
@@ -23,10 +23,15 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			traceID = uuid.NewString()
 		}
 
+		w.Header().Set("X-Request-ID", requestID)
 		lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		ctx := r.Context()
-		ctx = contextWithRequestInfo(ctx, requestID, traceID)
+		ctx = logger.WithRequestID(ctx, requestID)
+		ctx = logger.WithTraceID(ctx, traceID)
+		if actor := r.Header.Get("X-Actor"); actor != "" {
+			ctx = logger.WithActor(ctx, actor)
+		}
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(lrw, r)
@@ -54,16 +59,3 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.statusCode = code
 	lrw.ResponseWriter.WriteHeader(code)
 }
-
-type contextKey string
-
-const (
-	requestIDKey contextKey = "requestID"
-	traceIDKey   contextKey = "traceID"
-)
-
-func contextWithRequestInfo(ctx context.Context, requestID, traceID string) context.Context {
-	ctx = context.WithValue(ctx, requestIDKey, requestID)
-	ctx = context.WithValue(ctx, traceIDKey, traceID)
-	return ctx
-}
@@ -11,6 +11,7 @@ type ctxKey string
 const (
 	loggerRequestIDKey ctxKey = "x-request-id"
 	loggerTraceIDKey   ctxKey = "x-trace-id"
+	actorKey           ctxKey = "x-actor"
 )
 
 type Logger interface {
@@ -49,6 +50,23 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, loggerTraceIDKey, traceID)
 }
 
+// WithActor сохраняет в контексте автора запроса, он попадает в журнал изменений.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor возвращает автора запроса, сохранённого WithActor, или пустую строку.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// RequestID возвращает идентификатор запроса, сохранённый WithRequestID, или пустую строку.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(loggerRequestIDKey).(string)
+	return requestID
+}
+
 func (l *L) Info(ctx context.Context, msg string, fields ...zap.Field) {
 	requestID, _ := ctx.Value(loggerRequestIDKey).(string)
 
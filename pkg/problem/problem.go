@@ -0,0 +1,89 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807 (application/problem+json).
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"effective_mobile/pkg/logger"
+)
+
+// ContentType — медиатип ответа об ошибке.
+const ContentType = "application/problem+json"
+
+// Типы проблем. Клиенты различают ошибки по type, а не по тексту detail.
+const (
+	TypeBadRequest         = "/problems/bad-request"
+	TypeNotFound           = "/problems/not-found"
+	TypeConflict           = "/problems/conflict"
+	TypePreconditionFailed = "/problems/precondition-failed"
+	TypePreconditionNeeded = "/problems/precondition-required"
+	TypeUnsupportedMedia   = "/problems/unsupported-media-type"
+	TypeValidation         = "/problems/validation"
+	TypeInternal           = "/problems/internal"
+	TypeUnknown            = "about:blank"
+)
+
+// FieldError описывает нарушение, относящееся к конкретному полю запроса.
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Message string `json:"message" example:"must not be negative"`
+}
+
+// Problem — тело ответа об ошибке.
+type Problem struct {
+	Type      string       `json:"type" example:"/problems/validation"`
+	Title     string       `json:"title" example:"Unprocessable Entity"`
+	Status    int          `json:"status" example:"422"`
+	Detail    string       `json:"detail,omitempty" example:"subscription is invalid"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/subscriptions/42"`
+	RequestID string       `json:"request_id,omitempty" example:"3f0b8c2e-5a61-4c1e-9d7a-2b6f1e0c9a14"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// typeByStatus — тип проблемы по умолчанию для HTTP-статуса.
+var typeByStatus = map[int]string{
+	http.StatusBadRequest:           TypeBadRequest,
+	http.StatusNotFound:             TypeNotFound,
+	http.StatusConflict:             TypeConflict,
+	http.StatusPreconditionFailed:   TypePreconditionFailed,
+	http.StatusPreconditionRequired: TypePreconditionNeeded,
+	http.StatusUnsupportedMediaType: TypeUnsupportedMedia,
+	http.StatusUnprocessableEntity:  TypeValidation,
+	http.StatusInternalServerError:  TypeInternal,
+}
+
+// New создаёт проблему со статусом status, типом и заголовком по умолчанию.
+func New(status int, detail string, fields ...FieldError) Problem {
+	typ, ok := typeByStatus[status]
+	if !ok {
+		typ = TypeUnknown
+	}
+	return Problem{
+		Type:   typ,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Errors: fields,
+	}
+}
+
+// Write отправляет p, дополняя его путём запроса и идентификатором запроса из контекста.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = logger.RequestID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Error — аналог http.Error: отвечает проблемой со статусом status и описанием detail.
+func Error(w http.ResponseWriter, r *http.Request, status int, detail string, fields ...FieldError) {
+	Write(w, r, New(status, detail, fields...))
+}